@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"chatbot-go/internal/bot"
+	"chatbot-go/internal/engine"
+	"chatbot-go/internal/graph"
+	"chatbot-go/internal/transcript"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphFormat    string
+	graphHighlight string
+	graphOutput    string
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Export the bot's flow as a Graphviz DOT or Mermaid diagram",
+	Long: `Export the bot's flow as a diagram. Edges are labelled with the intent or
+branch condition that takes them; input nodes and terminal nodes are styled
+distinctly. --highlight marks the path taken in a saved session file or
+the first session of a transcript.`,
+	SilenceUsage: true,
+	RunE:         runGraph,
+}
+
+func init() {
+	graphCmd.Flags().StringVar(&graphFormat, "format", "dot", "Diagram format (dot, mermaid)")
+	graphCmd.Flags().StringVar(&graphHighlight, "highlight", "", "Saved session or transcript file whose path to highlight")
+	graphCmd.Flags().StringVarP(&graphOutput, "output", "o", "", "File to write the diagram to (default stdout)")
+	rootCmd.AddCommand(graphCmd)
+}
+
+func runGraph(cmd *cobra.Command, args []string) error {
+	b, err := bot.LoadFromFile(botFile)
+	if err != nil {
+		return fmt.Errorf("failed to load bot: %w", err)
+	}
+
+	var path []string
+	if graphHighlight != "" {
+		path, err = loadSessionPath(graphHighlight)
+		if err != nil {
+			return err
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	if graphOutput != "" {
+		f, err := os.Create(graphOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	g := graph.Build(b)
+	switch graphFormat {
+	case "dot":
+		err = g.WriteDOT(out, b.Name, path)
+	case "mermaid":
+		err = g.WriteMermaid(out, path)
+	default:
+		return fmt.Errorf("unknown graph format: %s", graphFormat)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write graph: %w", err)
+	}
+	return nil
+}
+
+// loadSessionPath reads the nodes visited in a saved session file, or in
+// the first session of a transcript
+func loadSessionPath(file string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+	var session engine.Session
+	if err := json.Unmarshal(data, &session); err == nil && session.CurrentNode != "" {
+		return session.Path(), nil
+	}
+
+	// A transcript holds one JSON object per line, so it is not a single document
+	turns, err := transcript.Read(bytes.NewReader(data))
+	if err != nil || len(turns) == 0 {
+		return nil, fmt.Errorf("%s is neither a session nor a transcript file", file)
+	}
+	return transcript.Path(transcript.Sessions(turns)[0]), nil
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+
+	"chatbot-go/internal/bot"
+	"chatbot-go/internal/validate"
+
+	"github.com/spf13/cobra"
+)
+
+var overlapsFormat string
+
+var overlapsCmd = &cobra.Command{
+	Use:   "overlaps",
+	Short: "Find intent examples that route to a different intent",
+	Long: `Run every example of every intent through the rule router and report the
+examples it sends to another intent, either because another intent lists the
+same example or because a similar input would be captured by another intent's
+substring or word match. Exits with an error status if any are found.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runOverlaps,
+}
+
+func init() {
+	overlapsCmd.Flags().StringVar(&overlapsFormat, "format", "text", "Output format (text, json)")
+	rootCmd.AddCommand(overlapsCmd)
+}
+
+func runOverlaps(cmd *cobra.Command, args []string) error {
+	if overlapsFormat != "text" && overlapsFormat != "json" {
+		return fmt.Errorf("unknown format: %s", overlapsFormat)
+	}
+
+	b, err := bot.LoadFromFile(botFile)
+	if err != nil {
+		return fmt.Errorf("failed to load bot: %w", err)
+	}
+
+	overlaps := validate.FindOverlaps(b)
+	if overlapsFormat == "json" {
+		if overlaps == nil {
+			overlaps = []validate.NodeOverlap{}
+		}
+		if err := writeJSON(cmd.OutOrStdout(), overlaps); err != nil {
+			return err
+		}
+	} else {
+		for _, overlap := range overlaps {
+			fmt.Fprintln(cmd.OutOrStdout(), overlap.Describe())
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%d overlapping example(s)\n", len(overlaps))
+	}
+
+	if len(overlaps) > 0 {
+		return fmt.Errorf("found %d overlapping intent example(s)", len(overlaps))
+	}
+	return nil
+}
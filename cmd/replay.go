@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"chatbot-go/internal/bot"
+	"chatbot-go/internal/engine"
+	"chatbot-go/internal/tmpl"
+	"chatbot-go/internal/transcript"
+
+	"github.com/spf13/cobra"
+)
+
+var replayFormat string
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <transcript.jsonl>",
+	Short: "Replay a recorded transcript against the current bot",
+	Long: `Replay every session of a transcript recorded with --transcript against the
+--bot file, answering LLM calls with the recorded responses, and report the
+first turn of each session whose routing, transition, messages or variables
+differ from the recording. Exits with an error status if any session diverges.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runReplay,
+}
+
+func init() {
+	replayCmd.Flags().StringVar(&replayFormat, "format", "text", "Output format (text, json)")
+	rootCmd.AddCommand(replayCmd)
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	if replayFormat != "text" && replayFormat != "json" {
+		return fmt.Errorf("unknown format: %s", replayFormat)
+	}
+
+	turns, err := transcript.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	if len(turns) == 0 {
+		return fmt.Errorf("transcript %s has no turns", args[0])
+	}
+
+	b, err := bot.LoadFromFile(botFile)
+	if err != nil {
+		return fmt.Errorf("failed to load bot: %w", err)
+	}
+	missing, err := tmpl.NewMissing(missingVars, missingText)
+	if err != nil {
+		return err
+	}
+
+	results, err := transcript.Replay(context.Background(), b, turns, engine.WithMissing(missing))
+	if err != nil {
+		return err
+	}
+
+	diverged := 0
+	for _, result := range results {
+		if result.Divergence != nil {
+			diverged++
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	if replayFormat == "json" {
+		if err := writeJSON(out, results); err != nil {
+			return err
+		}
+	} else {
+		writeReplayText(out, results)
+	}
+
+	if diverged > 0 {
+		return fmt.Errorf("%d of %d session(s) diverged", diverged, len(results))
+	}
+	return nil
+}
+
+// writeReplayText prints one line per session and the differences of any
+// divergent turn
+func writeReplayText(w io.Writer, results []transcript.Result) {
+	for _, result := range results {
+		if result.Divergence == nil {
+			fmt.Fprintf(w, "session %s: %d turn(s) replayed identically\n", result.Session, result.Turns)
+			continue
+		}
+
+		d := result.Divergence
+		turn := d.Action
+		if d.Action == "step" {
+			turn = fmt.Sprintf("say %q", d.Input)
+		}
+		fmt.Fprintf(w, "session %s: diverged at turn %d (%s)\n", result.Session, d.Turn, turn)
+		for _, difference := range d.Differences {
+			for _, line := range strings.Split(difference, "\n") {
+				fmt.Fprintf(w, "    %s\n", line)
+			}
+		}
+	}
+}
@@ -8,16 +8,25 @@ import (
 	"chatbot-go/internal/bot"
 	"chatbot-go/internal/engine"
 	"chatbot-go/internal/llm"
+	"chatbot-go/internal/tmpl"
+	"chatbot-go/internal/transcript"
 	"chatbot-go/internal/validate"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	botFile     string
-	llmType     string
-	ollamaURL   string
-	ollamaModel string
+	botFile        string
+	llmType        string
+	ollamaURL      string
+	ollamaModel    string
+	llmFixture     string
+	sessionID      string
+	sessionDir     string
+	ioMode         string
+	missingVars    string
+	missingText    string
+	transcriptFile string
 )
 
 var rootCmd = &cobra.Command{
@@ -29,22 +38,88 @@ and is LLM-ready for integration with local LLMs.`,
 }
 
 func init() {
-	rootCmd.Flags().StringVarP(&botFile, "bot", "b", "examples/support-bot.yaml", "Path to bot YAML file")
-	rootCmd.Flags().StringVarP(&llmType, "llm", "l", "noop", "LLM provider type (noop, ollama)")
-	rootCmd.Flags().StringVar(&ollamaURL, "ollama-url", "http://localhost:11434", "Ollama API URL")
-	rootCmd.Flags().StringVar(&ollamaModel, "ollama-model", "llama2", "Ollama model name")
+	rootCmd.PersistentFlags().StringVarP(&botFile, "bot", "b", "examples/support-bot.yaml", "Path to bot YAML file")
+	rootCmd.PersistentFlags().StringVarP(&llmType, "llm", "l", "noop", "LLM provider type (noop, ollama, scripted)")
+	rootCmd.PersistentFlags().StringVar(&ollamaURL, "ollama-url", "http://localhost:11434", "Ollama API URL")
+	rootCmd.PersistentFlags().StringVar(&ollamaModel, "ollama-model", "llama2", "Ollama model name")
+	rootCmd.PersistentFlags().StringVar(&llmFixture, "llm-fixture", "", "Fixture file of scripted LLM responses for --llm scripted")
+	rootCmd.PersistentFlags().StringVar(&missingVars, "missing-vars", "blank", "How messages render unset variables (blank, default, error)")
+	rootCmd.PersistentFlags().StringVar(&missingText, "missing-default", "", "Text shown for unset variables with --missing-vars=default")
+	rootCmd.Flags().StringVar(&sessionID, "session", "", "Session ID to save and resume the conversation under")
+	rootCmd.Flags().StringVar(&sessionDir, "session-dir", ".chatbot/sessions", "Directory for saved sessions")
+	rootCmd.Flags().StringVar(&ioMode, "io", "text", "Input/output format (text, jsonl)")
+	rootCmd.Flags().StringVar(&transcriptFile, "transcript", "", "Append a record of every turn to this transcript file")
 }
 
 func runChatbot(cmd *cobra.Command, args []string) error {
+	b, llmProvider, err := loadBot()
+	if err != nil {
+		return err
+	}
+
+	missing, err := tmpl.NewMissing(missingVars, missingText)
+	if err != nil {
+		return err
+	}
+
+	// Create and run engine, resuming a saved session if requested
+	opts := []engine.Option{engine.WithMissing(missing)}
+	if sessionID != "" {
+		opts = append(opts, engine.WithStore(engine.NewFileStore(sessionDir)))
+	}
+	if transcriptFile != "" {
+		recorder, err := transcript.Create(transcriptFile)
+		if err != nil {
+			return err
+		}
+		defer recorder.Close()
+		opts = append(opts, engine.WithRecorder(recorder))
+	}
+	conversationEngine := engine.NewConversationEngine(b, llmProvider, opts...)
+	if sessionID != "" {
+		if err := conversationEngine.OpenSession(sessionID); err != nil {
+			return err
+		}
+	}
+	ctx := context.Background()
+
+	switch ioMode {
+	case "text", "":
+		err = conversationEngine.Run(ctx)
+	case "jsonl":
+		err = conversationEngine.RunJSONL(ctx, os.Stdin, os.Stdout)
+	default:
+		return fmt.Errorf("unknown io mode: %s", ioMode)
+	}
+	if err != nil {
+		return fmt.Errorf("conversation error: %w", err)
+	}
+
+	return nil
+}
+
+// loadBot loads and validates the bot file and initializes the LLM provider
+func loadBot() (*bot.Bot, llm.Provider, error) {
+	return loadBotFile(botFile)
+}
+
+// loadBotFile loads and validates a bot file and initializes the LLM provider
+func loadBotFile(path string) (*bot.Bot, llm.Provider, error) {
 	// Load bot
-	b, err := bot.LoadFromFile(botFile)
+	b, err := bot.LoadFromFile(path)
 	if err != nil {
-		return fmt.Errorf("failed to load bot: %w", err)
+		return nil, nil, fmt.Errorf("failed to load bot: %w", err)
 	}
 
-	// Validate flow
-	if err := validate.ValidateFlow(b); err != nil {
-		return fmt.Errorf("flow validation failed: %w", err)
+	// Validate flow, reporting warnings without stopping
+	diagnostics := validate.Check(b)
+	for _, d := range diagnostics {
+		if d.Severity == validate.SeverityError {
+			return nil, nil, fmt.Errorf("flow validation failed: %s (run 'chatbot validate' for all problems)", d.Message)
+		}
+	}
+	for _, d := range diagnostics {
+		fmt.Fprintln(os.Stderr, d)
 	}
 
 	// Initialize LLM provider
@@ -52,21 +127,21 @@ func runChatbot(cmd *cobra.Command, args []string) error {
 	switch llmType {
 	case "ollama":
 		llmProvider = llm.NewOllamaProvider(ollamaURL, ollamaModel)
+	case "scripted":
+		if llmFixture == "" {
+			return nil, nil, fmt.Errorf("--llm scripted requires --llm-fixture")
+		}
+		llmProvider, err = llm.LoadScriptedProvider(llmFixture)
+		if err != nil {
+			return nil, nil, err
+		}
 	case "noop", "":
 		llmProvider = llm.NewNoopProvider()
 	default:
-		return fmt.Errorf("unknown LLM provider: %s", llmType)
+		return nil, nil, fmt.Errorf("unknown LLM provider: %s", llmType)
 	}
 
-	// Create and run engine
-	conversationEngine := engine.NewConversationEngine(b, llmProvider)
-	ctx := context.Background()
-
-	if err := conversationEngine.Run(ctx); err != nil {
-		return fmt.Errorf("conversation error: %w", err)
-	}
-
-	return nil
+	return b, llmProvider, nil
 }
 
 // Execute runs the root command
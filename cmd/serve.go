@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"chatbot-go/internal/engine"
+	"chatbot-go/internal/server"
+	"chatbot-go/internal/tmpl"
+	"chatbot-go/internal/transcript"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr         string
+	serveSessionTTL   time.Duration
+	serveAllowOrigins []string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the bot over an HTTP JSON API",
+	Long: `Serve the bot over an HTTP JSON API so web frontends can run conversations.
+Each client starts its own session and posts messages to it, or opens a
+WebSocket at /ws that is bound to a session for the life of the connection.`,
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "Address to listen on")
+	serveCmd.Flags().DurationVar(&serveSessionTTL, "session-ttl", 30*time.Minute, "Remove sessions idle for longer than this (0 keeps them forever)")
+	serveCmd.Flags().StringVar(&transcriptFile, "transcript", "", "Append a record of every turn of every session to this transcript file")
+	serveCmd.Flags().StringSliceVar(&serveAllowOrigins, "allow-origin", nil, "Extra origins allowed to open WebSocket connections (\"*\" for any)")
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	b, llmProvider, err := loadBot()
+	if err != nil {
+		return err
+	}
+
+	missing, err := tmpl.NewMissing(missingVars, missingText)
+	if err != nil {
+		return err
+	}
+
+	opts := []engine.Option{
+		engine.WithMissing(missing),
+		engine.WithSessionTTL(serveSessionTTL),
+	}
+	if transcriptFile != "" {
+		recorder, err := transcript.Create(transcriptFile)
+		if err != nil {
+			return err
+		}
+		defer recorder.Close()
+		opts = append(opts, engine.WithRecorder(recorder))
+	}
+
+	conversationEngine := engine.NewConversationEngine(b, llmProvider, opts...)
+	srv := server.New(conversationEngine, serveAllowOrigins...)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Check for idle sessions a few times per TTL
+	var expireEvery time.Duration
+	if serveSessionTTL > 0 {
+		expireEvery = serveSessionTTL / 4
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Serving %s on %s\n", b.Name, serveAddr)
+	if err := srv.ListenAndServe(ctx, serveAddr, expireEvery); err != nil {
+		return fmt.Errorf("server error: %w", err)
+	}
+	return nil
+}
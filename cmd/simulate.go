@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chatbot-go/internal/engine"
+	"chatbot-go/internal/simulate"
+	"chatbot-go/internal/tmpl"
+	"chatbot-go/internal/transcript"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	simulateSessions   int
+	simulateMaxTurns   int
+	simulateSeed       int64
+	simulateFormat     string
+	simulateTranscript string
+)
+
+var simulateCmd = &cobra.Command{
+	Use:     "simulate",
+	Aliases: []string{"fuzz"},
+	Short:   "Walk random conversations through the bot and report problems",
+	Long: `Walk random conversations through the bot: pick intents by their examples,
+answer typed inputs with valid and invalid values, and now and then send a
+global intent or unrecognized text. Reports turns that crash or fail, sub-flow
+loops, conversations that never end, messages with unrendered template
+placeholders, and terminal nodes no session reached.
+
+Walks are seeded, so the same --seed repeats the same conversations; each
+finding names the seed that repeats its session alone.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runSimulate,
+}
+
+func init() {
+	simulateCmd.Flags().IntVar(&simulateSessions, "sessions", 100, "Number of sessions to walk")
+	simulateCmd.Flags().IntVar(&simulateMaxTurns, "max-turns", 50, "Turns after which a session counts as never ending")
+	simulateCmd.Flags().Int64Var(&simulateSeed, "seed", 0, "Random seed (default: based on the current time)")
+	simulateCmd.Flags().StringVar(&simulateFormat, "format", "text", "Output format (text, json)")
+	simulateCmd.Flags().StringVar(&simulateTranscript, "transcript", "", "Append a record of every simulated turn to this transcript file")
+	rootCmd.AddCommand(simulateCmd)
+}
+
+func runSimulate(cmd *cobra.Command, args []string) error {
+	if simulateFormat != "text" && simulateFormat != "json" {
+		return fmt.Errorf("unknown format: %s", simulateFormat)
+	}
+	if simulateSessions < 1 || simulateMaxTurns < 1 {
+		return fmt.Errorf("--sessions and --max-turns must be at least 1")
+	}
+
+	b, llmProvider, err := loadBot()
+	if err != nil {
+		return err
+	}
+
+	missing, err := tmpl.NewMissing(missingVars, missingText)
+	if err != nil {
+		return err
+	}
+	opts := []engine.Option{engine.WithMissing(missing)}
+	if simulateTranscript != "" {
+		recorder, err := transcript.Create(simulateTranscript)
+		if err != nil {
+			return err
+		}
+		defer recorder.Close()
+		opts = append(opts, engine.WithRecorder(recorder))
+	}
+
+	seed := simulateSeed
+	if !cmd.Flags().Changed("seed") {
+		seed = time.Now().UnixNano() % 1_000_000
+	}
+
+	sim := simulate.New(b, llmProvider, simulate.Options{
+		Sessions: simulateSessions,
+		MaxTurns: simulateMaxTurns,
+		Seed:     seed,
+	}, opts...)
+	report := sim.Run(context.Background())
+
+	if simulateFormat == "json" {
+		if err := writeJSON(cmd.OutOrStdout(), report); err != nil {
+			return err
+		}
+	} else {
+		report.WriteText(cmd.OutOrStdout())
+	}
+
+	if report.HasErrors() {
+		return fmt.Errorf("simulation found errors")
+	}
+	return nil
+}
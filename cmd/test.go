@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"chatbot-go/internal/bot"
+	"chatbot-go/internal/bottest"
+	"chatbot-go/internal/coverage"
+	"chatbot-go/internal/engine"
+	"chatbot-go/internal/llm"
+	"chatbot-go/internal/tmpl"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	testCoverage       string
+	testCoverageOutput string
+)
+
+var testCmd = &cobra.Command{
+	Use:   "test <tests.yaml...>",
+	Short: "Run scripted conversation tests against a bot",
+	Long: `Run scripted conversation tests without a terminal. Each test plays user
+turns against a fresh session and checks the node reached, the messages,
+variables and whether the conversation ended. Tests run against the bot named
+in the test file, or the --bot file when it names none, with the scripted LLM
+fixture the file names, or the --llm provider. The command exits with an error
+status if any test fails.
+
+--coverage reports the nodes, intents and branches the tests exercised.`,
+	Args:          cobra.MinimumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runTest,
+}
+
+func init() {
+	testCmd.Flags().StringVar(&testCoverage, "coverage", "", "Report flow coverage (text, json, html)")
+	testCmd.Flags().Lookup("coverage").NoOptDefVal = "text"
+	testCmd.Flags().StringVar(&testCoverageOutput, "coverage-out", "", "File to write the coverage report to (default stdout)")
+	rootCmd.AddCommand(testCmd)
+}
+
+// testedBot is a bot loaded for the test command with its coverage
+type testedBot struct {
+	bot      *bot.Bot
+	provider llm.Provider
+	coverage *coverage.Coverage
+}
+
+func runTest(cmd *cobra.Command, args []string) error {
+	switch testCoverage {
+	case "", "text", "json", "html":
+	default:
+		return fmt.Errorf("unknown coverage format: %s", testCoverage)
+	}
+
+	missing, err := tmpl.NewMissing(missingVars, missingText)
+	if err != nil {
+		return err
+	}
+
+	// Load each bot once so tests of the same bot share its coverage
+	bots := make(map[string]*testedBot)
+	var order []*testedBot
+
+	// Keep JSON and HTML coverage on stdout apart from the test results
+	out := cmd.OutOrStdout()
+	results := out
+	if (testCoverage == "json" || testCoverage == "html") && testCoverageOutput == "" {
+		results = cmd.ErrOrStderr()
+	}
+
+	passed, failed := 0, 0
+	for _, path := range args {
+		file, err := bottest.LoadFile(path)
+		if err != nil {
+			return err
+		}
+
+		botPath := file.BotPath()
+		if botPath == "" {
+			botPath = botFile
+		}
+		tested, ok := bots[botPath]
+		if !ok {
+			b, llmProvider, err := loadBotFile(botPath)
+			if err != nil {
+				return err
+			}
+			tested = &testedBot{bot: b, provider: llmProvider, coverage: coverage.New(b)}
+			bots[botPath] = tested
+			order = append(order, tested)
+		}
+
+		llmProvider := tested.provider
+		if fixture := file.LLMFixturePath(); fixture != "" {
+			if llmProvider, err = llm.LoadScriptedProvider(fixture); err != nil {
+				return err
+			}
+		}
+
+		runner := bottest.NewRunner(tested.bot, llmProvider,
+			engine.WithMissing(missing),
+			engine.WithObserver(tested.coverage.Observe))
+		for _, result := range runner.Run(context.Background(), file) {
+			if result.Passed() {
+				passed++
+				fmt.Fprintf(results, "PASS %s: %s\n", result.File, result.Name)
+				continue
+			}
+			failed++
+			fmt.Fprintf(results, "FAIL %s: %s\n", result.File, result.Name)
+			for _, line := range strings.Split(result.Failure, "\n") {
+				fmt.Fprintf(results, "    %s\n", line)
+			}
+		}
+	}
+
+	fmt.Fprintf(results, "%d passed, %d failed\n", passed, failed)
+
+	if testCoverage != "" {
+		reports := make([]*coverage.Report, len(order))
+		for i, tested := range order {
+			reports[i] = tested.coverage.Report()
+		}
+		if err := writeCoverage(out, reports); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d test(s) failed", failed)
+	}
+	return nil
+}
+
+// writeCoverage writes the coverage reports in the requested format to
+// --coverage-out, or to out when no file is given
+func writeCoverage(out io.Writer, reports []*coverage.Report) error {
+	if testCoverageOutput != "" {
+		f, err := os.Create(testCoverageOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create coverage file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	switch testCoverage {
+	case "json":
+		return writeJSON(out, reports)
+	case "html":
+		return coverage.WriteHTML(out, reports)
+	default:
+		coverage.WriteText(out, reports)
+		return nil
+	}
+}
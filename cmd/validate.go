@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	"chatbot-go/internal/bot"
+	"chatbot-go/internal/validate"
+
+	"github.com/spf13/cobra"
+)
+
+var validateFormat string
+
+// yamlErrorLine finds the line number in a YAML parse error
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [bot.yaml...]",
+	Short: "Check bot files and report every problem found",
+	Long: `Check bot files and report every error and warning with its position in
+the YAML file. Validates the --bot file when no files are given, and exits
+with an error status if any file has errors.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runValidate,
+}
+
+// validateOutput is the JSON output of the validate command
+type validateOutput struct {
+	Diagnostics []validate.Diagnostic `json:"diagnostics"`
+	Errors      int                   `json:"errors"`
+	Warnings    int                   `json:"warnings"`
+}
+
+func init() {
+	validateCmd.Flags().StringVar(&validateFormat, "format", "text", "Output format (text, json)")
+	rootCmd.AddCommand(validateCmd)
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	if validateFormat != "text" && validateFormat != "json" {
+		return fmt.Errorf("unknown format: %s", validateFormat)
+	}
+
+	files := args
+	if len(files) == 0 {
+		files = []string{botFile}
+	}
+
+	output := validateOutput{Diagnostics: []validate.Diagnostic{}}
+	for _, file := range files {
+		output.Diagnostics = append(output.Diagnostics, validateFile(file)...)
+	}
+	for _, d := range output.Diagnostics {
+		if d.Severity == validate.SeverityError {
+			output.Errors++
+		} else {
+			output.Warnings++
+		}
+	}
+
+	if validateFormat == "json" {
+		if err := writeJSON(cmd.OutOrStdout(), output); err != nil {
+			return err
+		}
+	} else {
+		writeValidateText(cmd.OutOrStdout(), output)
+	}
+
+	if output.Errors > 0 {
+		return fmt.Errorf("validation failed with %d error(s)", output.Errors)
+	}
+	return nil
+}
+
+// validateFile checks one bot file; a file that cannot be parsed yields a
+// single error
+func validateFile(file string) []validate.Diagnostic {
+	b, err := bot.ParseFile(file)
+	if err != nil {
+		d := validate.Diagnostic{
+			Severity: validate.SeverityError,
+			File:     file,
+			Message:  err.Error(),
+		}
+		if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+			d.Line, _ = strconv.Atoi(match[1])
+			d.Column = 1
+		}
+		return []validate.Diagnostic{d}
+	}
+	return validate.Check(b)
+}
+
+// writeValidateText prints one line per diagnostic and a summary
+func writeValidateText(w io.Writer, output validateOutput) {
+	for _, d := range output.Diagnostics {
+		fmt.Fprintln(w, d)
+	}
+	fmt.Fprintf(w, "%d error(s), %d warning(s)\n", output.Errors, output.Warnings)
+}
+
+// writeJSON prints a value as an indented JSON document
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
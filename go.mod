@@ -3,6 +3,7 @@ module chatbot-go
 go 1.21
 
 require (
+	github.com/gorilla/websocket v1.5.0
 	github.com/spf13/cobra v1.8.0
 	gopkg.in/yaml.v3 v3.0.1
 )
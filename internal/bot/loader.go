@@ -9,27 +9,59 @@ import (
 
 // LoadFromFile loads a bot definition from a YAML file
 func LoadFromFile(path string) (*Bot, error) {
+	bot, err := ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bot.ValidateBasic(); err != nil {
+		return nil, err
+	}
+
+	if err := bot.CompileTemplates(); err != nil {
+		return nil, err
+	}
+
+	if err := bot.CompilePatterns(); err != nil {
+		return nil, err
+	}
+
+	return bot, nil
+}
+
+// ParseFile reads a bot definition and records where each key appears in
+// the file, without validating it
+func ParseFile(path string) (*Bot, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read bot file: %w", err)
 	}
 
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
 	var botDef struct {
-		Bot   Bot              `yaml:"bot"`
-		Flows map[string]*Node `yaml:"flows"`
+		Bot           Bot                 `yaml:"bot"`
+		Flows         map[string]*Node    `yaml:"flows"`
+		Subflows      map[string]*Subflow `yaml:"subflows"`
+		GlobalIntents *GlobalIntents      `yaml:"global_intents"`
+		Fallback      *Fallback           `yaml:"fallback"`
 	}
 
-	if err := yaml.Unmarshal(data, &botDef); err != nil {
+	if err := root.Decode(&botDef); err != nil {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
 
 	bot := &Bot{
-		Name:  botDef.Bot.Name,
-		Flows: botDef.Flows,
-	}
-
-	if err := bot.ValidateBasic(); err != nil {
-		return nil, err
+		Name:          botDef.Bot.Name,
+		Flows:         botDef.Flows,
+		Subflows:      botDef.Subflows,
+		GlobalIntents: botDef.GlobalIntents,
+		Fallback:      botDef.Fallback,
+		file:          path,
+		positions:     indexPositions(&root),
 	}
 
 	return bot, nil
@@ -0,0 +1,53 @@
+package bot
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// CompilePatterns compiles the pattern of every regex input and "matches"
+// branch condition so each is compiled once and invalid patterns are
+// reported when the bot is loaded
+func (b *Bot) CompilePatterns() error {
+	for _, name := range b.flowNames() {
+		node := b.Flows[name]
+		if node == nil {
+			continue
+		}
+		if in := node.Input; in != nil && in.Type == "regex" {
+			re, err := regexp.Compile(in.Pattern)
+			if err != nil {
+				return fmt.Errorf("node '%s' input pattern: %w", name, err)
+			}
+			in.pattern = re
+		}
+		for i, branch := range node.Branches {
+			if cond := branch.When; cond != nil && cond.Op == "matches" {
+				re, err := regexp.Compile(cond.Value)
+				if err != nil {
+					return fmt.Errorf("node '%s' branch %d pattern: %w", name, i+1, err)
+				}
+				cond.pattern = re
+			}
+		}
+	}
+	return nil
+}
+
+// Regexp returns the input's compiled pattern, compiling it now if the bot
+// was not loaded through LoadFromFile
+func (in *Input) Regexp() (*regexp.Regexp, error) {
+	if in.pattern != nil {
+		return in.pattern, nil
+	}
+	return regexp.Compile(in.Pattern)
+}
+
+// Regexp returns the compiled pattern of a "matches" condition, compiling it
+// now if the bot was not loaded through LoadFromFile
+func (c *Condition) Regexp() (*regexp.Regexp, error) {
+	if c.pattern != nil {
+		return c.pattern, nil
+	}
+	return regexp.Compile(c.Value)
+}
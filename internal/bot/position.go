@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Position is a line and column in the bot's YAML file, both starting at 1
+type Position struct {
+	Line   int
+	Column int
+}
+
+// File returns the path the bot was loaded from, if any
+func (b *Bot) File() string {
+	return b.file
+}
+
+// Position returns where a key path such as "flows.start.intents.0.next"
+// appears in the bot file. Paths that are not in the file resolve to their
+// nearest enclosing key, so a missing field points at its node.
+func (b *Bot) Position(path string) (Position, bool) {
+	for path != "" {
+		if pos, ok := b.positions[path]; ok {
+			return pos, true
+		}
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+	return Position{}, false
+}
+
+// indexPositions maps the key path of every mapping key and sequence item in
+// a YAML document to its position
+func indexPositions(root *yaml.Node) map[string]Position {
+	positions := make(map[string]Position)
+
+	var walk func(node *yaml.Node, path string)
+	walk = func(node *yaml.Node, path string) {
+		switch node.Kind {
+		case yaml.DocumentNode:
+			for _, child := range node.Content {
+				walk(child, path)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				childPath := joinPath(path, key.Value)
+				positions[childPath] = Position{Line: key.Line, Column: key.Column}
+				walk(value, childPath)
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				childPath := joinPath(path, strconv.Itoa(i))
+				positions[childPath] = Position{Line: item.Line, Column: item.Column}
+				walk(item, childPath)
+			}
+		}
+	}
+	walk(root, "")
+
+	return positions
+}
+
+// joinPath appends a key to a key path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
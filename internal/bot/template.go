@@ -0,0 +1,48 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+
+	"chatbot-go/internal/tmpl"
+)
+
+// CompileTemplates compiles every node's message so template syntax errors
+// are reported when the bot is loaded rather than mid-conversation
+func (b *Bot) CompileTemplates() error {
+	for _, name := range b.flowNames() {
+		node := b.Flows[name]
+		if node == nil {
+			continue
+		}
+		message, err := tmpl.Parse(name, node.Message)
+		if err != nil {
+			return fmt.Errorf("node '%s' message: %w", name, err)
+		}
+		if _, err := tmpl.Parse(name, node.Generate); err != nil {
+			return fmt.Errorf("node '%s' generate prompt: %w", name, err)
+		}
+		node.message = message
+	}
+	return nil
+}
+
+// MessageTemplate returns the node's compiled message, compiling it now if
+// the bot was not loaded through LoadFromFile
+func (n *Node) MessageTemplate() (*tmpl.Template, error) {
+	if n.message != nil {
+		return n.message, nil
+	}
+	return tmpl.Parse("message", n.Message)
+}
+
+// flowNames returns the names of the bot's nodes in sorted order, so load
+// errors are reported deterministically
+func (b *Bot) flowNames() []string {
+	names := make([]string, 0, len(b.Flows))
+	for name := range b.Flows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
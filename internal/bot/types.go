@@ -1,18 +1,54 @@
 package bot
 
+import (
+	"regexp"
+
+	"chatbot-go/internal/tmpl"
+)
+
 // Bot represents the complete bot definition loaded from YAML
 type Bot struct {
-	Name  string           `yaml:"name"`
-	Flows map[string]*Node `yaml:"flows"`
+	Name          string              `yaml:"name"`
+	Flows         map[string]*Node    `yaml:"flows"`
+	Subflows      map[string]*Subflow `yaml:"subflows,omitempty"`
+	GlobalIntents *GlobalIntents      `yaml:"global_intents,omitempty"`
+	Fallback      *Fallback           `yaml:"fallback,omitempty"`
+
+	file      string              // path the bot was loaded from
+	positions map[string]Position // key path to position in the file
+}
+
+// Subflow is a reusable sequence of nodes entered through its own start node
+type Subflow struct {
+	Start string `yaml:"start"`
 }
 
 // Node represents a single conversation node in the flow
 type Node struct {
-	Message string   `yaml:"message"`
-	Intents []Intent `yaml:"intents,omitempty"`
-	Input   *Input   `yaml:"input,omitempty"`
-	Actions []Action `yaml:"actions,omitempty"`
-	Next    string   `yaml:"next,omitempty"`
+	Message  string    `yaml:"message"`
+	Intents  []Intent  `yaml:"intents,omitempty"`
+	Input    *Input    `yaml:"input,omitempty"`
+	Actions  []Action  `yaml:"actions,omitempty"`
+	Branches []Branch  `yaml:"branches,omitempty"`
+	Call     *Call     `yaml:"call,omitempty"`
+	Fallback *Fallback `yaml:"fallback,omitempty"`
+	Generate string    `yaml:"generate,omitempty"` // optional LLM prompt whose output follows the message
+	Next     string    `yaml:"next,omitempty"`
+
+	message *tmpl.Template // compiled Message, set by CompileTemplates
+}
+
+// EndsFlow reports whether a node has no outgoing transition, ending either
+// the conversation or the active sub-flow. Input nodes never end a flow: they
+// wait for their answer first.
+func (n *Node) EndsFlow() bool {
+	return n.Next == "" && len(n.Intents) == 0 && len(n.Branches) == 0 && n.Call == nil && n.Input == nil
+}
+
+// Call enters a named sub-flow and resumes at Return once the sub-flow ends
+type Call struct {
+	Flow   string `yaml:"flow"`
+	Return string `yaml:"return"`
 }
 
 // Intent defines an intent that can be matched from user input
@@ -22,10 +58,73 @@ type Intent struct {
 	Next     string   `yaml:"next"`
 }
 
+// Fallback configures how a node responds when input matches none of its intents
+type Fallback struct {
+	Message   string `yaml:"message,omitempty"`
+	MaxMisses int    `yaml:"max_misses,omitempty"`
+	Next      string `yaml:"next,omitempty"` // node reached after max_misses consecutive misses
+}
+
+// FallbackFor returns the fallback in effect at a node: fields set on the node
+// override the bot-level fallback
+func (b *Bot) FallbackFor(node *Node) Fallback {
+	var fallback Fallback
+	if b.Fallback != nil {
+		fallback = *b.Fallback
+	}
+	if node.Fallback != nil {
+		if node.Fallback.Message != "" {
+			fallback.Message = node.Fallback.Message
+		}
+		if node.Fallback.MaxMisses != 0 {
+			fallback.MaxMisses = node.Fallback.MaxMisses
+		}
+		if node.Fallback.Next != "" {
+			fallback.Next = node.Fallback.Next
+		}
+	}
+	return fallback
+}
+
+// GlobalIntents are intents checked on every turn regardless of the current node
+type GlobalIntents struct {
+	Priority string         `yaml:"priority,omitempty"` // "before" (default) or "after" node intents
+	Intents  []GlobalIntent `yaml:"intents"`
+}
+
+// GlobalIntent is an intent that targets either a node or a built-in command
+type GlobalIntent struct {
+	Intent  `yaml:",inline"`
+	Builtin string `yaml:"builtin,omitempty"` // "restart" or "back"
+}
+
+// Branch defines a conditional transition evaluated against session variables.
+// A branch without a condition is the default and always matches.
+type Branch struct {
+	When *Condition `yaml:"when,omitempty"`
+	Next string     `yaml:"next"`
+}
+
+// Condition tests a single session variable
+type Condition struct {
+	Var   string `yaml:"var"`
+	Op    string `yaml:"op"` // eq (default), ne, gt, gte, lt, lte, exists, missing, matches
+	Value string `yaml:"value,omitempty"`
+
+	pattern *regexp.Regexp // compiled Value of "matches", set by CompilePatterns
+}
+
 // Input defines how to capture user input
 type Input struct {
-	Type   string `yaml:"type"` // "text" for now
-	SaveAs string `yaml:"save_as"`
+	Type       string   `yaml:"type"` // text, number, integer, email, phone, date, time, yes_no, choice, regex
+	SaveAs     string   `yaml:"save_as"`
+	Options    []string `yaml:"options,omitempty"` // allowed values for "choice"
+	Pattern    string   `yaml:"pattern,omitempty"` // regular expression for "regex"
+	Error      string   `yaml:"error,omitempty"`   // shown when the input is rejected
+	MaxRetries int      `yaml:"max_retries,omitempty"`
+	Fallback   string   `yaml:"fallback,omitempty"` // node reached after max_retries rejections
+
+	pattern *regexp.Regexp // compiled Pattern, set by CompilePatterns
 }
 
 // Action represents an action to execute
@@ -0,0 +1,112 @@
+package bottest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File is a set of conversation tests for one bot
+type File struct {
+	Bot        string `yaml:"bot,omitempty"`         // bot YAML, relative to the test file
+	LLMFixture string `yaml:"llm_fixture,omitempty"` // scripted LLM responses, relative to the test file
+	Tests      []Case `yaml:"tests"`
+
+	path string
+}
+
+// Case is one scripted conversation
+type Case struct {
+	Name  string `yaml:"name"`
+	Start Expect `yaml:"start,omitempty"` // checked against the opening response
+	Turns []Turn `yaml:"turns"`
+}
+
+// Turn is one user action and what the bot should do in response
+type Turn struct {
+	Say    *string `yaml:"say,omitempty"`
+	Back   bool    `yaml:"back,omitempty"`
+	Expect `yaml:",inline"`
+}
+
+// Expect lists the checks made after a turn; unset fields are not checked
+type Expect struct {
+	Node      string            `yaml:"node,omitempty"`
+	Message   *string           `yaml:"message,omitempty"`  // all messages, one per line
+	Contains  Strings           `yaml:"contains,omitempty"` // each must appear in the messages
+	Variables map[string]string `yaml:"variables,omitempty"`
+	Terminal  *bool             `yaml:"terminal,omitempty"`
+	Error     string            `yaml:"error,omitempty"` // the turn must fail with this text
+}
+
+// Strings is a list that may be written as a single string in YAML
+type Strings []string
+
+// UnmarshalYAML accepts a string or a list of strings
+func (s *Strings) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*s = Strings{value.Value}
+		return nil
+	}
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// LoadFile reads a test file
+func LoadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test file: %w", err)
+	}
+
+	var file File
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse test file: %w", err)
+	}
+	file.path = path
+
+	if len(file.Tests) == 0 {
+		return nil, fmt.Errorf("test file %s has no tests", path)
+	}
+	for i, c := range file.Tests {
+		if c.Name == "" {
+			return nil, fmt.Errorf("test %d in %s requires a name", i+1, path)
+		}
+		for j, turn := range c.Turns {
+			if (turn.Say != nil) == turn.Back {
+				return nil, fmt.Errorf("test '%s' turn %d must either say something or go back", c.Name, j+1)
+			}
+		}
+	}
+	return &file, nil
+}
+
+// Path returns the file the tests were loaded from
+func (f *File) Path() string {
+	return f.path
+}
+
+// BotPath returns the bot the file tests, or "" if it does not name one
+func (f *File) BotPath() string {
+	return f.resolve(f.Bot)
+}
+
+// LLMFixturePath returns the scripted LLM fixture the tests run with, or ""
+// if the file does not name one
+func (f *File) LLMFixturePath() string {
+	return f.resolve(f.LLMFixture)
+}
+
+// resolve makes a path named in the file relative to the file's directory
+func (f *File) resolve(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(f.path), path)
+}
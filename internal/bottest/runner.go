@@ -0,0 +1,155 @@
+package bottest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"chatbot-go/internal/bot"
+	"chatbot-go/internal/engine"
+	"chatbot-go/internal/llm"
+	"chatbot-go/internal/textdiff"
+)
+
+// Result is the outcome of one test case
+type Result struct {
+	File    string
+	Name    string
+	Failure string // empty when the case passed
+}
+
+// Passed reports whether the case passed
+func (r Result) Passed() bool {
+	return r.Failure == ""
+}
+
+// Runner runs conversation tests headlessly against a bot
+type Runner struct {
+	bot      *bot.Bot
+	provider llm.Provider
+	opts     []engine.Option
+}
+
+// NewRunner creates a runner; each case gets a fresh engine built with the
+// given provider and options
+func NewRunner(b *bot.Bot, provider llm.Provider, opts ...engine.Option) *Runner {
+	return &Runner{
+		bot:      b,
+		provider: provider,
+		opts:     opts,
+	}
+}
+
+// Run runs every case in a test file
+func (r *Runner) Run(ctx context.Context, file *File) []Result {
+	results := make([]Result, 0, len(file.Tests))
+	for _, c := range file.Tests {
+		result := Result{File: file.Path(), Name: c.Name}
+		if err := r.runCase(ctx, c); err != nil {
+			result.Failure = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// runCase plays a case's turns and stops at the first failed expectation
+func (r *Runner) runCase(ctx context.Context, c Case) error {
+	ce := engine.NewConversationEngine(r.bot, r.provider, r.opts...)
+	sessionID := ce.SessionID()
+
+	resp, err := ce.Start(ctx, sessionID)
+	if err := r.check(ce, sessionID, c.Start, resp, err); err != nil {
+		return fmt.Errorf("start: %w", err)
+	}
+
+	for i, turn := range c.Turns {
+		var (
+			resp  engine.Response
+			err   error
+			label string
+		)
+		if turn.Back {
+			resp, err = ce.Back(ctx, sessionID)
+			label = "back"
+		} else {
+			resp, err = ce.Step(ctx, sessionID, *turn.Say)
+			label = fmt.Sprintf("say %q", *turn.Say)
+		}
+		if err := r.check(ce, sessionID, turn.Expect, resp, err); err != nil {
+			return fmt.Errorf("turn %d (%s): %w", i+1, label, err)
+		}
+	}
+	return nil
+}
+
+// check compares a response and the session after it with the expectations
+func (r *Runner) check(ce *engine.ConversationEngine, sessionID string, want Expect, resp engine.Response, stepErr error) error {
+	if want.Error != "" {
+		if stepErr == nil {
+			return fmt.Errorf("expected error containing %q, got none", want.Error)
+		}
+		if !strings.Contains(stepErr.Error(), want.Error) {
+			return fmt.Errorf("expected error containing %q, got %q", want.Error, stepErr.Error())
+		}
+		return nil
+	}
+	if stepErr != nil {
+		return fmt.Errorf("unexpected error: %v", stepErr)
+	}
+
+	var problems []string
+	if want.Node != "" && resp.Node != want.Node {
+		problems = append(problems, fmt.Sprintf("node: want %s, got %s", want.Node, resp.Node))
+	}
+
+	messages := strings.Join(resp.Messages, "\n")
+	if want.Message != nil && messages != strings.TrimRight(*want.Message, "\n") {
+		problems = append(problems, "message differs (- want, + got):\n"+textdiff.Diff(strings.TrimRight(*want.Message, "\n"), messages))
+	}
+	for _, text := range want.Contains {
+		if !strings.Contains(messages, text) {
+			problems = append(problems, fmt.Sprintf("message does not contain %q:\n%s", text, indent(messages)))
+		}
+	}
+
+	if want.Terminal != nil && resp.Terminal != *want.Terminal {
+		problems = append(problems, fmt.Sprintf("terminal: want %t, got %t", *want.Terminal, resp.Terminal))
+	}
+
+	if len(want.Variables) > 0 {
+		session, err := ce.Session(sessionID)
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(want.Variables))
+		for name := range want.Variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			got, ok := session.Variables[name]
+			switch {
+			case !ok:
+				problems = append(problems, fmt.Sprintf("variable %s: want %q, not set", name, want.Variables[name]))
+			case got != want.Variables[name]:
+				problems = append(problems, fmt.Sprintf("variable %s: want %q, got %q", name, want.Variables[name], got))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// indent prefixes every line of text for display under a failure
+func indent(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = "    " + line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,174 @@
+package capture
+
+import (
+	"chatbot-go/internal/bot"
+	"fmt"
+	"math"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// decimalNumber matches an optionally signed number with an optional
+// fractional part
+var decimalNumber = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+
+// dateLayouts are the accepted date formats, normalized to 2006-01-02
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006/01/02",
+	"01/02/2006",
+	"1/2/2006",
+	"2 Jan 2006",
+	"2 January 2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+}
+
+// timeLayouts are the accepted time formats, normalized to 15:04
+var timeLayouts = []string{
+	"15:04",
+	"15:04:05",
+	"3:04pm",
+	"3:04 pm",
+	"3pm",
+	"3 pm",
+}
+
+// Parse validates raw user input against the input definition and returns the
+// normalized value to store
+func Parse(in *bot.Input, raw string) (string, error) {
+	value := strings.TrimSpace(raw)
+
+	switch in.Type {
+	case "text", "":
+		return value, nil
+	case "number":
+		return parseNumber(value)
+	case "integer":
+		return parseInteger(value)
+	case "email":
+		return parseEmail(value)
+	case "phone":
+		return parsePhone(value)
+	case "date":
+		return parseTime(value, dateLayouts, "2006-01-02", "Please enter a date, e.g. 2024-03-15.")
+	case "time":
+		return parseTime(strings.ToLower(value), timeLayouts, "15:04", "Please enter a time, e.g. 10:30am.")
+	case "yes_no":
+		return parseYesNo(value)
+	case "choice":
+		return parseChoice(value, in.Options)
+	case "regex":
+		return parseRegex(value, in)
+	default:
+		return "", fmt.Errorf("unknown input type: %s", in.Type)
+	}
+}
+
+// parseNumber accepts a plain decimal number; forms ParseFloat also knows,
+// such as exponents, hex floats, "NaN" and "Inf", are rejected
+func parseNumber(value string) (string, error) {
+	if !decimalNumber.MatchString(value) {
+		return "", ErrInvalidInput("Please enter a number.")
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", ErrInvalidInput("Please enter a number.")
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64), nil
+}
+
+// parseInteger accepts a whole number
+func parseInteger(value string) (string, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return "", ErrInvalidInput("Please enter a whole number.")
+	}
+	return strconv.Itoa(n), nil
+}
+
+// parseEmail accepts a bare email address and lowercases it
+func parseEmail(value string) (string, error) {
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value || !strings.Contains(value[strings.LastIndex(value, "@"):], ".") {
+		return "", ErrInvalidInput("Please enter a valid email address.")
+	}
+	return strings.ToLower(addr.Address), nil
+}
+
+// parsePhone accepts digits with common separators and an optional leading +
+func parsePhone(value string) (string, error) {
+	var digits strings.Builder
+	for i, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", ErrInvalidInput("Please enter a valid phone number.")
+		}
+	}
+
+	if digits.Len() < 7 || digits.Len() > 15 {
+		return "", ErrInvalidInput("Please enter a valid phone number.")
+	}
+	if strings.HasPrefix(value, "+") {
+		return "+" + digits.String(), nil
+	}
+	return digits.String(), nil
+}
+
+// parseTime accepts any of the given layouts and reformats the value
+func parseTime(value string, layouts []string, normalized, message string) (string, error) {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(normalized), nil
+		}
+	}
+	return "", ErrInvalidInput(message)
+}
+
+// parseYesNo maps common affirmative and negative answers to "yes" or "no"
+func parseYesNo(value string) (string, error) {
+	switch strings.ToLower(value) {
+	case "yes", "y", "yeah", "yep", "sure", "ok", "okay", "true":
+		return "yes", nil
+	case "no", "n", "nope", "nah", "false":
+		return "no", nil
+	default:
+		return "", ErrInvalidInput("Please answer yes or no.")
+	}
+}
+
+// parseChoice accepts one of the options, ignoring case, and returns it as declared
+func parseChoice(value string, options []string) (string, error) {
+	for _, option := range options {
+		if strings.EqualFold(option, value) {
+			return option, nil
+		}
+	}
+	return "", ErrInvalidInput(fmt.Sprintf("Please choose one of: %s.", strings.Join(options, ", ")))
+}
+
+// parseRegex accepts input matching the input's pattern
+func parseRegex(value string, in *bot.Input) (string, error) {
+	re, err := in.Regexp()
+	if err != nil {
+		return "", fmt.Errorf("invalid input pattern '%s': %w", in.Pattern, err)
+	}
+	if !re.MatchString(value) {
+		return "", ErrInvalidInput("That doesn't look right. Please try again.")
+	}
+	return value, nil
+}
+
+// ErrInvalidInput indicates user input was rejected; the text is shown to the user
+type ErrInvalidInput string
+
+func (e ErrInvalidInput) Error() string {
+	return string(e)
+}
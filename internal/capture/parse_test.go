@@ -0,0 +1,53 @@
+package capture
+
+import (
+	"testing"
+
+	"chatbot-go/internal/bot"
+)
+
+func TestParseNumber(t *testing.T) {
+	in := &bot.Input{Type: "number"}
+	valid := map[string]string{
+		"42":    "42",
+		"-7":    "-7",
+		"+3.50": "3.5",
+		"0.25":  "0.25",
+		".5":    "0.5",
+		"2.":    "2",
+	}
+	for raw, want := range valid {
+		if got, err := Parse(in, raw); err != nil || got != want {
+			t.Errorf("Parse(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+
+	for _, raw := range []string{"NaN", "nan", "Inf", "-Inf", "infinity", "0x1p3", "1e3", "1_000", "1,5", "", "."} {
+		if got, err := Parse(in, raw); err == nil {
+			t.Errorf("Parse(%q) = %q, want an error", raw, got)
+		}
+	}
+}
+
+func TestParseRegexUsesCompiledPattern(t *testing.T) {
+	b := &bot.Bot{Flows: map[string]*bot.Node{
+		"start": {Input: &bot.Input{Type: "regex", Pattern: `^[A-Z]{2}\d{3}$`}},
+	}}
+	if err := b.CompilePatterns(); err != nil {
+		t.Fatalf("CompilePatterns: %v", err)
+	}
+	in := b.Flows["start"].Input
+	// The compiled pattern wins over later edits to the source
+	in.Pattern = "("
+
+	if got, err := Parse(in, "AB123"); err != nil || got != "AB123" {
+		t.Errorf("Parse(AB123) = %q, %v", got, err)
+	}
+	if _, err := Parse(in, "ab123"); err == nil {
+		t.Error("Parse(ab123): expected the input to be rejected")
+	}
+
+	if err := b.CompilePatterns(); err == nil {
+		t.Error("CompilePatterns: expected an invalid pattern error")
+	}
+}
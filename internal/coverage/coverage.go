@@ -0,0 +1,123 @@
+package coverage
+
+import (
+	"sync"
+
+	"chatbot-go/internal/bot"
+	"chatbot-go/internal/engine"
+	"chatbot-go/internal/graph"
+)
+
+// Coverage counts the nodes, intents and branches of a bot exercised by
+// conversations. It is safe for concurrent use.
+type Coverage struct {
+	mu       sync.Mutex
+	bot      *bot.Bot
+	nodes    map[string]int
+	intents  map[intentKey]int
+	branches map[branchKey]int
+}
+
+// intentKey identifies an intent of a node, or a global intent when node is empty
+type intentKey struct {
+	node   string
+	intent string
+}
+
+// branchKey identifies a branch of a node, or its next when index is -1
+type branchKey struct {
+	node  string
+	index int
+}
+
+// New creates an empty coverage record for a bot
+func New(b *bot.Bot) *Coverage {
+	return &Coverage{
+		bot:      b,
+		nodes:    make(map[string]int),
+		intents:  make(map[intentKey]int),
+		branches: make(map[branchKey]int),
+	}
+}
+
+// Observe records an engine event; pass it to engine.WithObserver
+func (c *Coverage) Observe(e engine.Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch e.Kind {
+	case engine.EventVisit:
+		c.nodes[e.Node]++
+	case engine.EventIntent:
+		c.intents[intentKey{node: e.Node, intent: e.Intent}]++
+	case engine.EventGlobal:
+		c.intents[intentKey{intent: e.Intent}]++
+	case engine.EventBranch:
+		c.branches[branchKey{node: e.Node, index: e.Branch}]++
+	}
+}
+
+// Report lists every node, intent and branch of the bot with the number of
+// times it was exercised, in the order nodes are reached from start
+func (c *Coverage) Report() *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r := &Report{
+		Bot:      c.bot.Name,
+		File:     c.bot.File(),
+		Nodes:    []NodeHit{},
+		Intents:  []IntentHit{},
+		Branches: []BranchHit{},
+	}
+	for _, n := range graph.Build(c.bot).Nodes {
+		node := c.bot.Flows[n.Name]
+		r.Nodes = append(r.Nodes, NodeHit{Node: n.Name, Hits: c.nodes[n.Name]})
+
+		for _, intent := range node.Intents {
+			r.Intents = append(r.Intents, IntentHit{
+				Node:   n.Name,
+				Intent: intent.Name,
+				Next:   intent.Next,
+				Hits:   c.intents[intentKey{node: n.Name, intent: intent.Name}],
+			})
+		}
+
+		for i, branch := range node.Branches {
+			label := "otherwise"
+			if branch.When != nil {
+				label = graph.DescribeCondition(branch.When)
+			}
+			r.Branches = append(r.Branches, BranchHit{
+				Node:  n.Name,
+				Index: i,
+				Label: label,
+				Next:  branch.Next,
+				Hits:  c.branches[branchKey{node: n.Name, index: i}],
+			})
+		}
+		if len(node.Branches) > 0 && node.Next != "" {
+			r.Branches = append(r.Branches, BranchHit{
+				Node:  n.Name,
+				Index: -1,
+				Label: "otherwise",
+				Next:  node.Next,
+				Hits:  c.branches[branchKey{node: n.Name, index: -1}],
+			})
+		}
+	}
+
+	if c.bot.GlobalIntents != nil {
+		for _, global := range c.bot.GlobalIntents.Intents {
+			r.Intents = append(r.Intents, IntentHit{
+				Intent:  global.Name,
+				Next:    global.Next,
+				Builtin: global.Builtin,
+				Hits:    c.intents[intentKey{intent: global.Name}],
+			})
+		}
+	}
+
+	r.total()
+	return r
+}
@@ -0,0 +1,59 @@
+package coverage
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+)
+
+// htmlReport is the page written by WriteHTML
+var htmlReport = template.Must(template.New("coverage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Flow coverage</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: left; }
+td.hits { text-align: right; }
+tr.covered { background: #e6f4ea; }
+tr.uncovered { background: #fce8e6; }
+</style>
+</head>
+<body>
+<h1>Flow coverage</h1>
+{{range .}}
+<h2>{{.Bot}}{{with .File}} <small>({{.}})</small>{{end}}</h2>
+<p>Nodes {{.Totals.Nodes}} &middot; Intents {{.Totals.Intents}} &middot; Branches {{.Totals.Branches}}</p>
+
+<h3>Nodes</h3>
+<table>
+<tr><th>Node</th><th>Hits</th></tr>
+{{range .Nodes}}<tr class="{{if .Hits}}covered{{else}}uncovered{{end}}"><td>{{.Node}}</td><td class="hits">{{.Hits}}</td></tr>
+{{end}}</table>
+
+{{if .Intents}}<h3>Intents</h3>
+<table>
+<tr><th>Node</th><th>Intent</th><th>Leads to</th><th>Hits</th></tr>
+{{range .Intents}}<tr class="{{if .Hits}}covered{{else}}uncovered{{end}}"><td>{{.Source}}</td><td>{{.Intent}}</td><td>{{.Target}}</td><td class="hits">{{.Hits}}</td></tr>
+{{end}}</table>
+{{end}}
+{{if .Branches}}<h3>Branches</h3>
+<table>
+<tr><th>Node</th><th>Condition</th><th>Leads to</th><th>Hits</th></tr>
+{{range .Branches}}<tr class="{{if .Hits}}covered{{else}}uncovered{{end}}"><td>{{.Node}}</td><td>{{.Label}}</td><td>{{.Next}}</td><td class="hits">{{.Hits}}</td></tr>
+{{end}}</table>
+{{end}}
+{{end}}
+</body>
+</html>
+`))
+
+// WriteHTML writes the reports as a standalone HTML page
+func WriteHTML(w io.Writer, reports []*Report) error {
+	if err := htmlReport.Execute(w, reports); err != nil {
+		return fmt.Errorf("failed to write HTML: %w", err)
+	}
+	return nil
+}
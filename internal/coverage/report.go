@@ -0,0 +1,144 @@
+package coverage
+
+import (
+	"fmt"
+	"io"
+)
+
+// Report is the coverage of one bot
+type Report struct {
+	Bot      string      `json:"bot"`
+	File     string      `json:"file,omitempty"`
+	Totals   Totals      `json:"totals"`
+	Nodes    []NodeHit   `json:"nodes"`
+	Intents  []IntentHit `json:"intents"`
+	Branches []BranchHit `json:"branches"`
+}
+
+// Totals summarizes how much of each kind of item was exercised
+type Totals struct {
+	Nodes    Ratio `json:"nodes"`
+	Intents  Ratio `json:"intents"`
+	Branches Ratio `json:"branches"`
+}
+
+// Ratio counts covered items out of a total
+type Ratio struct {
+	Covered int `json:"covered"`
+	Total   int `json:"total"`
+}
+
+// NodeHit is a node and the number of times conversations arrived at it
+type NodeHit struct {
+	Node string `json:"node"`
+	Hits int    `json:"hits"`
+}
+
+// IntentHit is an intent and the number of times input matched it. Node is
+// empty for global intents.
+type IntentHit struct {
+	Node    string `json:"node,omitempty"`
+	Intent  string `json:"intent"`
+	Next    string `json:"next,omitempty"`
+	Builtin string `json:"builtin,omitempty"`
+	Hits    int    `json:"hits"`
+}
+
+// BranchHit is a branch of a node and the number of times it was taken.
+// Index is the branch's position, or -1 for the node's next after its
+// branches.
+type BranchHit struct {
+	Node  string `json:"node"`
+	Index int    `json:"index"`
+	Label string `json:"label"`
+	Next  string `json:"next"`
+	Hits  int    `json:"hits"`
+}
+
+// String formats the ratio as covered/total and a percentage
+func (r Ratio) String() string {
+	if r.Total == 0 {
+		return "0/0"
+	}
+	return fmt.Sprintf("%d/%d (%.1f%%)", r.Covered, r.Total, r.Percent())
+}
+
+// Percent returns the covered share of the total, or 100 when there is
+// nothing to cover
+func (r Ratio) Percent() float64 {
+	if r.Total == 0 {
+		return 100
+	}
+	return float64(r.Covered) * 100 / float64(r.Total)
+}
+
+// total computes the report's totals from its items
+func (r *Report) total() {
+	r.Totals = Totals{}
+	for _, n := range r.Nodes {
+		r.Totals.Nodes.add(n.Hits)
+	}
+	for _, i := range r.Intents {
+		r.Totals.Intents.add(i.Hits)
+	}
+	for _, b := range r.Branches {
+		r.Totals.Branches.add(b.Hits)
+	}
+}
+
+// add counts one item exercised hits times
+func (r *Ratio) add(hits int) {
+	r.Total++
+	if hits > 0 {
+		r.Covered++
+	}
+}
+
+// Source names where the intent is declared
+func (i IntentHit) Source() string {
+	if i.Node == "" {
+		return "global"
+	}
+	return i.Node
+}
+
+// Target names where the intent leads
+func (i IntentHit) Target() string {
+	if i.Builtin != "" {
+		return i.Builtin
+	}
+	return i.Next
+}
+
+// WriteText prints each report's totals followed by the items never exercised
+func WriteText(w io.Writer, reports []*Report) {
+	for _, r := range reports {
+		fmt.Fprintf(w, "Coverage of %s (%s)\n", r.Bot, r.File)
+		fmt.Fprintf(w, "  nodes:    %s\n", r.Totals.Nodes)
+		fmt.Fprintf(w, "  intents:  %s\n", r.Totals.Intents)
+		fmt.Fprintf(w, "  branches: %s\n", r.Totals.Branches)
+
+		var uncovered []string
+		for _, n := range r.Nodes {
+			if n.Hits == 0 {
+				uncovered = append(uncovered, fmt.Sprintf("node %s", n.Node))
+			}
+		}
+		for _, i := range r.Intents {
+			if i.Hits == 0 {
+				uncovered = append(uncovered, fmt.Sprintf("intent %s.%s -> %s", i.Source(), i.Intent, i.Target()))
+			}
+		}
+		for _, b := range r.Branches {
+			if b.Hits == 0 {
+				uncovered = append(uncovered, fmt.Sprintf("branch %s [%s] -> %s", b.Node, b.Label, b.Next))
+			}
+		}
+		if len(uncovered) > 0 {
+			fmt.Fprintln(w, "  not covered:")
+			for _, item := range uncovered {
+				fmt.Fprintf(w, "    %s\n", item)
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+
+	"chatbot-go/internal/bot"
+)
+
+// EvaluateCondition reports whether a branch condition holds for the given variables
+func EvaluateCondition(cond *bot.Condition, vars map[string]string) (bool, error) {
+	value, exists := vars[cond.Var]
+
+	switch cond.Op {
+	case "eq", "":
+		return exists && value == cond.Value, nil
+	case "ne":
+		return !exists || value != cond.Value, nil
+	case "exists":
+		return exists, nil
+	case "missing":
+		return !exists, nil
+	case "matches":
+		re, err := cond.Regexp()
+		if err != nil {
+			return false, fmt.Errorf("invalid pattern for '%s': %w", cond.Var, err)
+		}
+		return exists && re.MatchString(value), nil
+	case "gt", "gte", "lt", "lte":
+		want, err := strconv.ParseFloat(cond.Value, 64)
+		if err != nil {
+			return false, fmt.Errorf("operator '%s' requires a numeric value, got '%s'", cond.Op, cond.Value)
+		}
+		// Unset or non-numeric variables never satisfy a comparison
+		got, err := strconv.ParseFloat(value, 64)
+		if !exists || err != nil {
+			return false, nil
+		}
+		return compareNumbers(cond.Op, got, want), nil
+	default:
+		return false, fmt.Errorf("unknown condition operator: %s", cond.Op)
+	}
+}
+
+// compareNumbers applies a numeric comparison operator
+func compareNumbers(op string, got, want float64) bool {
+	switch op {
+	case "gt":
+		return got > want
+	case "gte":
+		return got >= want
+	case "lt":
+		return got < want
+	default:
+		return got <= want
+	}
+}
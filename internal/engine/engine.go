@@ -3,62 +3,350 @@ package engine
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"chatbot-go/internal/actions"
 	"chatbot-go/internal/bot"
+	"chatbot-go/internal/capture"
 	"chatbot-go/internal/llm"
 	"chatbot-go/internal/render"
 	"chatbot-go/internal/router"
+	"chatbot-go/internal/tmpl"
 )
 
-// ConversationEngine orchestrates the conversation flow
+// backCommand is the CLI command that undoes the previous turn
+const backCommand = "/back"
+
+// defaultFallbackMessage is shown when input matches no intent and no fallback message is configured
+const defaultFallbackMessage = "I didn't understand that. Please try again."
+
+// maxAutoTransitions bounds the sub-flow calls and returns followed in one step
+const maxAutoTransitions = 100
+
+// Response is the bot output produced for a single step of the conversation
+type Response struct {
+	SessionID string
+	Node      string
+	Messages  []string
+	Intents   []bot.Intent
+	Terminal  bool
+}
+
+// ConversationEngine orchestrates the conversation flow. It is safe for
+// concurrent use across sessions.
 type ConversationEngine struct {
-	engine      *Engine
+	bot         *bot.Bot
+	sessions    *Manager
+	sessionID   string
 	ruleRouter  router.Router
 	llmRouter   *router.LLMRouter
 	llmProvider llm.Provider
-	renderer    *render.CLIRenderer
-	executor    *actions.Executor
+	renderer    render.Renderer
+	missing     tmpl.Missing
+	store       SessionStore
+	sessionTTL  time.Duration
+	observer    ObserveFunc
+	recorder    Recorder
+}
+
+// Option configures a ConversationEngine
+type Option func(*ConversationEngine)
+
+// WithStore persists sessions to the given store after every step
+func WithStore(store SessionStore) Option {
+	return func(ce *ConversationEngine) {
+		ce.store = store
+	}
+}
+
+// WithRenderer replaces the default stdin/stdout CLI renderer
+func WithRenderer(renderer render.Renderer) Option {
+	return func(ce *ConversationEngine) {
+		ce.renderer = renderer
+	}
+}
+
+// WithMissing sets how messages and prompts render variables that are not
+// set; by default they are left blank
+func WithMissing(missing tmpl.Missing) Option {
+	return func(ce *ConversationEngine) {
+		ce.missing = missing
+	}
 }
 
-// NewConversationEngine creates a new conversation engine
-func NewConversationEngine(b *bot.Bot, llmProvider llm.Provider) *ConversationEngine {
-	eng := NewEngine(b)
-	return &ConversationEngine{
-		engine:      eng,
+// WithSessionTTL lets ExpireSessions remove sessions idle for longer than ttl
+func WithSessionTTL(ttl time.Duration) Option {
+	return func(ce *ConversationEngine) {
+		ce.sessionTTL = ttl
+	}
+}
+
+// WithObserver reports what happens during each step to fn, such as the
+// nodes visited and the intents and branches taken. Events are reported once
+// the step is saved, so steps that fail and are rolled back report none.
+// Steps of different sessions may call fn concurrently.
+func WithObserver(fn ObserveFunc) Option {
+	return func(ce *ConversationEngine) {
+		ce.observer = fn
+	}
+}
+
+// NewConversationEngine creates a new conversation engine with one session
+// ready for the CLI driver
+func NewConversationEngine(b *bot.Bot, llmProvider llm.Provider, opts ...Option) *ConversationEngine {
+	ce := &ConversationEngine{
+		bot:         b,
 		ruleRouter:  router.NewRuleRouter(),
 		llmRouter:   router.NewLLMRouter(llmProvider),
 		llmProvider: llmProvider,
-		renderer:    render.NewCLIRenderer(),
-		executor:    actions.NewExecutor(eng),
+		renderer:    render.NewCLIRenderer(os.Stdin, os.Stdout),
+		missing:     tmpl.Missing{Mode: tmpl.MissingBlank},
+		store:       NewMemoryStore(),
+	}
+	for _, opt := range opts {
+		opt(ce)
+	}
+	if ce.recorder != nil && llmProvider != nil {
+		ce.llmProvider = recordLLM(llmProvider)
+		ce.llmRouter = router.NewLLMRouter(ce.llmProvider)
 	}
+
+	ce.sessions = NewManager(b, ce.store, ce.sessionTTL)
+	// A fresh ID cannot collide with an empty manager
+	ce.sessionID, _ = ce.sessions.Create()
+	return ce
 }
 
-// Run starts the conversation loop
-func (ce *ConversationEngine) Run(ctx context.Context) error {
-	for {
-		// Get current node
-		node, err := ce.engine.GetCurrentNode()
+// SessionID returns the ID of the session driven by Run
+func (ce *ConversationEngine) SessionID() string {
+	return ce.sessionID
+}
+
+// OpenSession makes Run drive the session with the given ID, resuming it
+// from the store if it was saved before and starting it fresh otherwise
+func (ce *ConversationEngine) OpenSession(id string) error {
+	if err := ce.sessions.Open(id); err != nil {
+		return fmt.Errorf("failed to open session: %w", err)
+	}
+	if id != ce.sessionID {
+		if err := ce.sessions.Delete(ce.sessionID); err != nil {
+			return err
+		}
+		ce.sessionID = id
+	}
+	return nil
+}
+
+// NewSession starts a new session and returns its ID
+func (ce *ConversationEngine) NewSession() (string, error) {
+	return ce.sessions.Create()
+}
+
+// Session returns a copy of the session with the given ID
+func (ce *ConversationEngine) Session(id string) (*Session, error) {
+	return ce.sessions.Get(id)
+}
+
+// DeleteSession ends and removes the session with the given ID
+func (ce *ConversationEngine) DeleteSession(id string) error {
+	return ce.sessions.Delete(id)
+}
+
+// ExpireSessions removes sessions idle for longer than the session TTL and
+// returns their IDs
+func (ce *ConversationEngine) ExpireSessions() ([]string, error) {
+	return ce.sessions.ExpireIdle()
+}
+
+// Start renders the current node of a session without consuming any input
+func (ce *ConversationEngine) Start(ctx context.Context, sessionID string) (Response, error) {
+	return ce.turn(ctx, sessionID, "start", "", func(ctx context.Context, eng *Engine) (Response, error) {
+		return ce.start(ctx, eng, Response{SessionID: sessionID})
+	})
+}
+
+// turn runs one Start, Step or Back on a session as a unit: if any part of
+// it fails, the session is restored to its state before the turn, so the
+// live session always matches the last one saved
+func (ce *ConversationEngine) turn(ctx context.Context, sessionID, action, input string, run func(context.Context, *Engine) (Response, error)) (Response, error) {
+	eng, release, err := ce.sessions.acquire(sessionID)
+	if err != nil {
+		return Response{}, err
+	}
+	defer release()
+
+	saved := eng.GetSession().Clone()
+	ctx, events := ce.bufferEvents(ctx)
+	ctx, rec := ce.beginRecord(ctx, eng, action, input)
+	resp, err := run(ctx, eng)
+	if err != nil {
+		eng.restore(saved)
+	}
+	if err := ce.finishRecord(rec, eng, resp, err); err != nil {
+		eng.restore(saved)
+		return Response{}, err
+	}
+	if err != nil {
+		return Response{}, err
+	}
+
+	resp, err = ce.save(eng, resp)
+	if err != nil {
+		eng.restore(saved)
+		return Response{}, err
+	}
+	ce.emitEvents(events)
+	return resp, nil
+}
+
+// start settles and presents the current node of the session held by eng
+func (ce *ConversationEngine) start(ctx context.Context, eng *Engine, resp Response) (Response, error) {
+	if err := ce.settle(ctx, eng, &resp); err != nil {
+		return Response{}, err
+	}
+	if err := ce.present(ctx, eng, &resp); err != nil {
+		return Response{}, err
+	}
+	return resp, nil
+}
+
+// Step feeds one user utterance to a session, runs routing, actions and the
+// resulting transition, and returns the bot output for the node reached
+func (ce *ConversationEngine) Step(ctx context.Context, sessionID, input string) (Response, error) {
+	return ce.turn(ctx, sessionID, "step", input, func(ctx context.Context, eng *Engine) (Response, error) {
+		return ce.step(ctx, eng, input)
+	})
+}
+
+// step runs a single turn against the session held by eng
+func (ce *ConversationEngine) step(ctx context.Context, eng *Engine, input string) (Response, error) {
+	before := eng.Snapshot()
+	node, err := eng.GetCurrentNode()
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to get current node: %w", err)
+	}
+
+	isTerminal, err := eng.IsTerminal()
+	if err != nil {
+		return Response{}, err
+	}
+	if isTerminal {
+		return Response{}, ErrConversationEnded{}
+	}
+
+	resp := Response{SessionID: eng.GetSession().ID}
+	message, err := ce.renderMessage(eng, node)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to render message: %w", err)
+	}
+
+	if global, ok := ce.matchGlobal(eng, node, input, "before"); ok {
+		return ce.applyGlobal(ctx, eng, global, before, input, message, resp)
+	}
+
+	var (
+		routed bool
+		next   string
+		value  = input
+	)
+	switch {
+	case node.Input != nil:
+		// Validate and normalize the input before saving it
+		value, err = capture.Parse(node.Input, input)
 		if err != nil {
-			return fmt.Errorf("failed to get current node: %w", err)
+			return ce.rejectInput(ctx, eng, node, err, before, input, message, resp)
 		}
+		eng.SetVariable(node.Input.SaveAs, value)
 
-		// Render and display message
-		message := ce.renderer.RenderMessage(node, ce.engine.GetSession())
-		ce.renderer.PrintMessage(message)
+	case len(node.Intents) > 0:
+		intentName, routedBy, ok := ce.route(ctx, input, node.Intents)
+		if !ok {
+			if global, ok := ce.matchGlobal(eng, node, input, "after"); ok {
+				return ce.applyGlobal(ctx, eng, global, before, input, message, resp)
+			}
 
-		// Check if terminal
-		isTerminal, err := ce.engine.IsTerminal()
+			return ce.miss(ctx, eng, node, before, input, message, resp)
+		}
+
+		for _, intent := range node.Intents {
+			if intent.Name == intentName {
+				next = intent.Next
+				break
+			}
+		}
+		ce.observe(ctx, Event{Kind: EventIntent, Node: before.Node, Intent: intentName, Router: routedBy})
+		routed = true
+	}
+
+	// Execute any actions
+	executor := actions.NewExecutor(eng)
+	for _, action := range node.Actions {
+		if err := executor.Execute(action, value); err != nil {
+			return Response{}, fmt.Errorf("action execution failed: %w", err)
+		}
+	}
+
+	// Transition to the matched intent's node, or follow the node's own
+	// branches and next once actions have updated the variables
+	if routed {
+		if next != "" {
+			if err := eng.Transition(next); err != nil {
+				return Response{}, fmt.Errorf("transition failed: %w", err)
+			}
+		}
+	} else {
+		branch, err := eng.Advance()
 		if err != nil {
-			return err
+			return Response{}, fmt.Errorf("transition failed: %w", err)
+		}
+		if len(node.Branches) > 0 {
+			ce.observe(ctx, Event{Kind: EventBranch, Node: before.Node, Branch: branch})
 		}
-		if isTerminal {
+	}
+
+	// Record turn in history
+	eng.AddTurn(before, input, message)
+
+	if err := ce.settle(ctx, eng, &resp); err != nil {
+		return Response{}, err
+	}
+	if err := ce.present(ctx, eng, &resp); err != nil {
+		return Response{}, err
+	}
+	return resp, nil
+}
+
+// Back undoes the most recent turn of a session, restoring its node and
+// variables, and returns the bot output for the restored node
+func (ce *ConversationEngine) Back(ctx context.Context, sessionID string) (Response, error) {
+	return ce.turn(ctx, sessionID, "back", "", func(ctx context.Context, eng *Engine) (Response, error) {
+		return ce.back(ctx, eng, Response{SessionID: sessionID})
+	})
+}
+
+// Run drives the session through the renderer until a terminal node is
+// reached; failed turns are reported and the conversation carries on
+func (ce *ConversationEngine) Run(ctx context.Context) error {
+	sessionID := ce.SessionID()
+
+	resp, err := ce.Start(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	for {
+		for _, message := range resp.Messages {
+			ce.renderer.PrintMessage(message)
+		}
+		if resp.Terminal {
 			return nil
 		}
 
 		// Show available intents if any
-		if len(node.Intents) > 0 {
-			ce.renderer.ShowIntents(node.Intents)
+		if len(resp.Intents) > 0 {
+			ce.renderer.ShowIntents(resp.Intents)
 		}
 
 		// Read user input
@@ -67,79 +355,344 @@ func (ce *ConversationEngine) Run(ctx context.Context) error {
 			return fmt.Errorf("failed to read input: %w", err)
 		}
 
-		// Handle input capture (if node has input definition)
-		if node.Input != nil {
-			// Save input directly to variable
-			ce.engine.SetVariable(node.Input.SaveAs, userInput)
-
-			// Execute any actions
-			for _, action := range node.Actions {
-				if err := ce.executor.Execute(action, userInput); err != nil {
-					return fmt.Errorf("action execution failed: %w", err)
-				}
+		var stepErr error
+		if userInput == backCommand {
+			resp, stepErr = ce.Back(ctx, sessionID)
+		} else {
+			resp, stepErr = ce.Step(ctx, sessionID, userInput)
+		}
+		if stepErr != nil {
+			// Report the failed turn and show where the conversation stands
+			ce.renderer.PrintError(stepErr)
+			if resp, err = ce.Start(ctx, sessionID); err != nil {
+				return err
 			}
+		}
+	}
+}
 
-			// Transition to next node
-			if node.Next != "" {
-				if err := ce.engine.Transition(node.Next); err != nil {
-					return fmt.Errorf("transition failed: %w", err)
-				}
-			}
-			continue
+// save persists the session after a successful step
+func (ce *ConversationEngine) save(eng *Engine, resp Response) (Response, error) {
+	if err := ce.store.Save(eng.GetSession()); err != nil {
+		return Response{}, fmt.Errorf("failed to save session: %w", err)
+	}
+	return resp, nil
+}
+
+// route matches input against intents, trying the rule router first and
+// falling back to the LLM router, and names the router that matched
+func (ce *ConversationEngine) route(ctx context.Context, input string, intents []bot.Intent) (string, string, bool) {
+	intentName, err := ce.ruleRouter.Route(input, intents)
+	if err == nil {
+		return intentName, "rule", true
+	}
+
+	// If rule router fails, try LLM router (if available)
+	if ce.llmProvider == nil {
+		return "", "", false
+	}
+	intentName, err = ce.llmRouter.Route(ctx, input, intents)
+	if err != nil {
+		return "", "", false
+	}
+	return intentName, "llm", true
+}
+
+// rejectInput reprompts after invalid input, or routes to the input's
+// fallback node once max_retries rejections have been reached
+func (ce *ConversationEngine) rejectInput(ctx context.Context, eng *Engine, node *bot.Node, cause error, before Snapshot, input, message string, resp Response) (Response, error) {
+	if _, ok := cause.(capture.ErrInvalidInput); !ok {
+		return Response{}, fmt.Errorf("input capture failed: %w", cause)
+	}
+
+	session := eng.GetSession()
+	session.Retries++
+	if node.Input.MaxRetries > 0 && session.Retries >= node.Input.MaxRetries && node.Input.Fallback != "" {
+		if err := eng.Transition(node.Input.Fallback); err != nil {
+			return Response{}, fmt.Errorf("transition failed: %w", err)
 		}
+		eng.AddTurn(before, input, message)
 
-		// Handle intent-based routing
-		if len(node.Intents) > 0 {
-			// Try rule router first
-			intentName, err := ce.ruleRouter.Route(userInput, node.Intents)
-			if err != nil {
-				// If rule router fails, try LLM router (if available)
-				if ce.llmProvider != nil {
-					intentName, err = ce.llmRouter.Route(ctx, userInput, node.Intents)
-					if err != nil {
-						ce.renderer.PrintMessage("I didn't understand that. Please try again.")
-						continue
-					}
-				} else {
-					ce.renderer.PrintMessage("I didn't understand that. Please try again.")
-					continue
-				}
-			}
+		if err := ce.settle(ctx, eng, &resp); err != nil {
+			return Response{}, err
+		}
+	} else if node.Input.Error != "" {
+		ce.say(ctx, &resp, node.Input.Error)
+	} else {
+		ce.say(ctx, &resp, cause.Error())
+	}
+
+	if err := ce.present(ctx, eng, &resp); err != nil {
+		return Response{}, err
+	}
+	return resp, nil
+}
+
+// miss answers input that matched no intent with the node's fallback
+// message, escalating to the fallback node after max_misses in a row
+func (ce *ConversationEngine) miss(ctx context.Context, eng *Engine, node *bot.Node, before Snapshot, input, message string, resp Response) (Response, error) {
+	ce.observe(ctx, Event{Kind: EventMiss, Node: before.Node})
+	fallback := eng.bot.FallbackFor(node)
+
+	session := eng.GetSession()
+	session.Retries++
+	if fallback.MaxMisses > 0 && session.Retries >= fallback.MaxMisses && fallback.Next != "" {
+		if err := eng.Transition(fallback.Next); err != nil {
+			return Response{}, fmt.Errorf("transition failed: %w", err)
+		}
+		eng.AddTurn(before, input, message)
+
+		if err := ce.settle(ctx, eng, &resp); err != nil {
+			return Response{}, err
+		}
+	} else if fallback.Message != "" {
+		ce.say(ctx, &resp, fallback.Message)
+	} else {
+		ce.say(ctx, &resp, defaultFallbackMessage)
+	}
 
-			// Find the matched intent and transition
-			for _, intent := range node.Intents {
-				if intent.Name == intentName {
-					// Execute any actions
-					for _, action := range node.Actions {
-						if err := ce.executor.Execute(action, userInput); err != nil {
-							return fmt.Errorf("action execution failed: %w", err)
-						}
-					}
-
-					// Transition to next node
-					if intent.Next != "" {
-						if err := ce.engine.Transition(intent.Next); err != nil {
-							return fmt.Errorf("transition failed: %w", err)
-						}
-					}
-					break
-				}
+	if err := ce.present(ctx, eng, &resp); err != nil {
+		return Response{}, err
+	}
+	return resp, nil
+}
+
+// back undoes the most recent turn and presents the restored node
+func (ce *ConversationEngine) back(ctx context.Context, eng *Engine, resp Response) (Response, error) {
+	if err := eng.Back(); err != nil {
+		if _, ok := err.(ErrNoHistory); !ok {
+			return Response{}, fmt.Errorf("back failed: %w", err)
+		}
+		ce.say(ctx, &resp, "There is nothing to go back to.")
+	}
+
+	if err := ce.present(ctx, eng, &resp); err != nil {
+		return Response{}, err
+	}
+	return resp, nil
+}
+
+// matchGlobal matches input against the bot's global intents when they are
+// checked at the given priority. Nodes without intents accept any text, so
+// there global intents are always checked first and only an exact match
+// counts, so answers like "art" are not mistaken for commands like "start
+// over".
+func (ce *ConversationEngine) matchGlobal(eng *Engine, node *bot.Node, input, priority string) (*bot.GlobalIntent, bool) {
+	globals := eng.bot.GlobalIntents
+	if globals == nil || len(globals.Intents) == 0 || strings.TrimSpace(input) == "" {
+		return nil, false
+	}
+
+	intents := make([]bot.Intent, len(globals.Intents))
+	for i, global := range globals.Intents {
+		intents[i] = global.Intent
+	}
+
+	checkAt := globals.Priority
+	if checkAt == "" || len(node.Intents) == 0 {
+		checkAt = "before"
+	}
+	if checkAt != priority {
+		return nil, false
+	}
+
+	var (
+		intentName string
+		ok         bool
+	)
+	if len(node.Intents) == 0 {
+		intentName, ok = router.MatchExact(input, intents)
+	} else {
+		name, err := ce.ruleRouter.Route(input, intents)
+		intentName, ok = name, err == nil
+	}
+	if !ok {
+		return nil, false
+	}
+
+	for i := range globals.Intents {
+		if globals.Intents[i].Name == intentName {
+			return &globals.Intents[i], true
+		}
+	}
+	return nil, false
+}
+
+// applyGlobal runs a matched global intent's built-in command or jumps to its node
+func (ce *ConversationEngine) applyGlobal(ctx context.Context, eng *Engine, global *bot.GlobalIntent, before Snapshot, input, message string, resp Response) (Response, error) {
+	ce.observe(ctx, Event{Kind: EventGlobal, Node: before.Node, Intent: global.Name, Router: "rule"})
+	switch global.Builtin {
+	case "back":
+		return ce.back(ctx, eng, resp)
+	case "restart":
+		if err := eng.Restart(); err != nil {
+			return Response{}, fmt.Errorf("restart failed: %w", err)
+		}
+		eng.AddTurn(before, input, message)
+	case "":
+		if err := eng.Jump(global.Next); err != nil {
+			return Response{}, fmt.Errorf("transition failed: %w", err)
+		}
+		eng.AddTurn(before, input, message)
+	default:
+		return Response{}, fmt.Errorf("unknown built-in command: %s", global.Builtin)
+	}
+
+	if err := ce.settle(ctx, eng, &resp); err != nil {
+		return Response{}, err
+	}
+	if err := ce.present(ctx, eng, &resp); err != nil {
+		return Response{}, err
+	}
+	return resp, nil
+}
+
+// settle follows sub-flow calls and returns from the current node until it
+// reaches a node that waits for user input, collecting the messages of the
+// nodes passed through
+func (ce *ConversationEngine) settle(ctx context.Context, eng *Engine, resp *Response) error {
+	for i := 0; i < maxAutoTransitions; i++ {
+		node, err := eng.GetCurrentNode()
+		if err != nil {
+			return fmt.Errorf("failed to get current node: %w", err)
+		}
+
+		ce.observe(ctx, Event{Kind: EventVisit, Node: eng.GetSession().CurrentNode})
+
+		atEnd, err := eng.AtFlowEnd()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case node.Call != nil:
+			if err := ce.appendMessage(ctx, eng, node, resp); err != nil {
+				return err
+			}
+			if err := eng.Call(node.Call); err != nil {
+				return fmt.Errorf("sub-flow call failed: %w", err)
 			}
-		} else if node.Next != "" {
-			// No intents, just transition to next
-			// Execute any actions first
-			for _, action := range node.Actions {
-				if err := ce.executor.Execute(action, userInput); err != nil {
-					return fmt.Errorf("action execution failed: %w", err)
-				}
+		case atEnd && len(eng.GetSession().Stack) > 0:
+			if err := ce.appendMessage(ctx, eng, node, resp); err != nil {
+				return err
 			}
+			if err := eng.Return(); err != nil {
+				return fmt.Errorf("sub-flow return failed: %w", err)
+			}
+		default:
+			return nil
+		}
+	}
+
+	return ErrTransitionLimit(eng.GetSession().CurrentNode)
+}
+
+// renderMessage renders a node's compiled message template with the
+// session's variables
+func (ce *ConversationEngine) renderMessage(eng *Engine, node *bot.Node) (string, error) {
+	t, err := node.MessageTemplate()
+	if err != nil {
+		return "", err
+	}
+	return t.Execute(eng.GetSession().GetVariables(), ce.missing)
+}
 
-			if err := ce.engine.Transition(node.Next); err != nil {
-				return fmt.Errorf("transition failed: %w", err)
+// renderText renders template text, such as an LLM prompt, with the
+// session's variables
+func (ce *ConversationEngine) renderText(eng *Engine, text string) (string, error) {
+	return tmpl.Render(text, eng.GetSession().GetVariables(), ce.missing)
+}
+
+// appendMessage adds a node's rendered message, unless it is empty, and any
+// LLM-generated text to the response
+func (ce *ConversationEngine) appendMessage(ctx context.Context, eng *Engine, node *bot.Node, resp *Response) error {
+	if node.Message != "" {
+		message, err := ce.renderMessage(eng, node)
+		if err != nil {
+			return fmt.Errorf("failed to render message: %w", err)
+		}
+		ce.say(ctx, resp, message)
+	}
+	return ce.generate(ctx, eng, node, resp)
+}
+
+// generate asks the LLM provider for the node's generated text, streaming it
+// when both the provider and the caller support it. The LLM is assistive
+// only, so generation failures leave the response unchanged; only a prompt
+// that cannot be rendered is an error.
+func (ce *ConversationEngine) generate(ctx context.Context, eng *Engine, node *bot.Node, resp *Response) error {
+	if node.Generate == "" || ce.llmProvider == nil {
+		return nil
+	}
+
+	promptText, err := ce.renderText(eng, node.Generate)
+	if err != nil {
+		return fmt.Errorf("failed to render generate prompt: %w", err)
+	}
+	prompt := llm.Prompt{Text: promptText}
+
+	var (
+		text     string
+		streamed bool
+	)
+	stream := streamFrom(ctx)
+	if streamer, ok := ce.llmProvider.(llm.StreamingProvider); ok && stream != nil {
+		text, err = streamer.StreamText(ctx, prompt, func(chunk string) {
+			streamed = true
+			stream(StreamChunk, chunk)
+		})
+	} else {
+		text, err = ce.llmProvider.GenerateText(ctx, prompt)
+	}
+
+	text = strings.TrimSpace(text)
+	if err != nil || text == "" {
+		// Listeners already hold chunks that no message will complete
+		if streamed {
+			reason := "no text was generated"
+			if err != nil {
+				reason = fmt.Sprintf("text generation failed: %v", err)
 			}
+			stream(StreamAbort, reason)
 		}
+		return nil
+	}
+	ce.say(ctx, resp, text)
+	return nil
+}
+
+// say appends a message to the response and streams it to any listener
+func (ce *ConversationEngine) say(ctx context.Context, resp *Response, text string) {
+	resp.Messages = append(resp.Messages, text)
+	if stream := streamFrom(ctx); stream != nil {
+		stream(StreamMessage, text)
+	}
+}
+
+// present appends the current node's rendered message, intents and terminal
+// state to the response
+func (ce *ConversationEngine) present(ctx context.Context, eng *Engine, resp *Response) error {
+	node, err := eng.GetCurrentNode()
+	if err != nil {
+		return fmt.Errorf("failed to get current node: %w", err)
+	}
+
+	isTerminal, err := eng.IsTerminal()
+	if err != nil {
+		return err
+	}
+
+	message, err := ce.renderMessage(eng, node)
+	if err != nil {
+		return fmt.Errorf("failed to render message: %w", err)
+	}
 
-		// Record turn in history
-		ce.engine.AddTurn(node.Message, userInput, message)
+	resp.Node = eng.GetSession().CurrentNode
+	ce.say(ctx, resp, message)
+	if err := ce.generate(ctx, eng, node, resp); err != nil {
+		return err
 	}
+	resp.Intents = node.Intents
+	resp.Terminal = isTerminal
+	return nil
 }
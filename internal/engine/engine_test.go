@@ -0,0 +1,299 @@
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"chatbot-go/internal/bot"
+	"chatbot-go/internal/llm"
+	"chatbot-go/internal/render"
+	"chatbot-go/internal/tmpl"
+)
+
+// loadTestBot loads a bot definition written inline in a test
+func loadTestBot(t *testing.T, definition string) *bot.Bot {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bot.yaml")
+	if err := os.WriteFile(path, []byte(definition), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b, err := bot.LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("failed to load bot: %v", err)
+	}
+	return b
+}
+
+func TestStepRestoresSessionOnError(t *testing.T) {
+	b := loadTestBot(t, `
+bot:
+  name: atomic
+flows:
+  start:
+    message: "Name?"
+    input: {type: text, save_as: name}
+    next: missing
+`)
+	ce := NewConversationEngine(b, llm.NewNoopProvider())
+	ctx := context.Background()
+	id := ce.SessionID()
+
+	if _, err := ce.Start(ctx, id); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if _, err := ce.Step(ctx, id, "Ada"); err == nil {
+		t.Fatal("Step: expected a transition error")
+	}
+
+	session, err := ce.Session(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.CurrentNode != "start" || len(session.Variables) != 0 || len(session.History) != 0 {
+		t.Errorf("session changed by failed step: node %s, variables %v, %d turn(s)",
+			session.CurrentNode, session.Variables, len(session.History))
+	}
+
+	// The conversation can carry on from the restored state
+	resp, err := ce.Start(ctx, id)
+	if err != nil {
+		t.Fatalf("Start after failed step: %v", err)
+	}
+	if resp.Node != "start" {
+		t.Errorf("node after failed step: got %s, want start", resp.Node)
+	}
+}
+
+func TestRunDrivesConversationThroughRenderer(t *testing.T) {
+	b := loadTestBot(t, `
+bot:
+  name: greeter
+flows:
+  start:
+    message: "Name?"
+    input: {type: text, save_as: name}
+    branches:
+      - when: {var: name, op: eq, value: "nobody"}
+        next: missing
+      - next: greet
+  greet:
+    message: "Hi {{name}}"
+`)
+	in := strings.NewReader("nobody\n/back\nAda\n")
+	var out strings.Builder
+	ce := NewConversationEngine(b, llm.NewNoopProvider(), WithRenderer(render.NewCLIRenderer(in, &out)))
+	if err := ce.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	want := "Name?\n" +
+		"> Error: transition failed: node 'missing' does not exist\n" +
+		"Name?\n" +
+		"> There is nothing to go back to.\n" +
+		"Name?\n" +
+		"> Hi Ada\n"
+	if out.String() != want {
+		t.Errorf("transcript:\ngot:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestInputNodeWaitsBeforeSubflowReturn(t *testing.T) {
+	b := loadTestBot(t, `
+bot:
+  name: ship
+subflows:
+  address:
+    start: ask_street
+flows:
+  start:
+    message: "Shipping"
+    call: {flow: address, return: confirm}
+  ask_street:
+    message: "Street?"
+    input: {type: text, save_as: street}
+    next: ask_city
+  ask_city:
+    message: "City?"
+    input: {type: text, save_as: city}
+  confirm:
+    message: "Shipping to {{street}}, {{city}}"
+`)
+	ce := NewConversationEngine(b, llm.NewNoopProvider())
+	ctx := context.Background()
+	id := ce.SessionID()
+
+	if _, err := ce.Start(ctx, id); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	resp, err := ce.Step(ctx, id, "Main St")
+	if err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+	if resp.Node != "ask_city" || resp.Terminal {
+		t.Errorf("after street: got node %s (terminal %v), want to wait at ask_city", resp.Node, resp.Terminal)
+	}
+}
+
+func TestGlobalIntentsNeedExactMatchWithoutIntents(t *testing.T) {
+	b := loadTestBot(t, `
+bot:
+  name: notes
+global_intents:
+  intents:
+    - name: restart
+      examples: ["start over", "restart"]
+      builtin: restart
+flows:
+  start:
+    message: "What do you like?"
+    next: thanks
+  thanks:
+    message: "Noted."
+`)
+	ce := NewConversationEngine(b, llm.NewNoopProvider())
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		input string
+		want  string
+	}{
+		{"art", "thanks"},
+		{"start over", "start"},
+	} {
+		id, err := ce.NewSession()
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := ce.Step(ctx, id, tc.input)
+		if err != nil {
+			t.Fatalf("Step(%q): %v", tc.input, err)
+		}
+		if resp.Node != tc.want {
+			t.Errorf("Step(%q): got node %s, want %s", tc.input, resp.Node, tc.want)
+		}
+	}
+}
+
+func TestRunJSONLReportsFailedTurns(t *testing.T) {
+	b := loadTestBot(t, `
+bot:
+  name: atomic
+flows:
+  start:
+    message: "Name?"
+    input: {type: text, save_as: name}
+    branches:
+      - when: {var: name, op: eq, value: "nobody"}
+        next: broken
+      - next: greet
+  broken:
+    message: "Hi {{nickname}}"
+  greet:
+    message: "Hi {{name}}"
+`)
+	ce := NewConversationEngine(b, llm.NewNoopProvider(), WithMissing(tmpl.Missing{Mode: tmpl.MissingError}))
+	in := strings.NewReader(`{"text": "nobody"}` + "\n" + `{"text": "Ada"}` + "\n")
+	var out strings.Builder
+	if err := ce.RunJSONL(context.Background(), in, &out); err != nil {
+		t.Fatalf("RunJSONL: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d output lines, want 3:\n%s", len(lines), out.String())
+	}
+	if !strings.Contains(lines[1], `"error"`) {
+		t.Errorf("failed turn: got %s, want an error line", lines[1])
+	}
+	if !strings.Contains(lines[2], `"Hi Ada"`) {
+		t.Errorf("after the error: got %s, want the greeting", lines[2])
+	}
+}
+
+func TestObserverSkipsFailedSteps(t *testing.T) {
+	b := loadTestBot(t, `
+bot:
+  name: greeter
+flows:
+  start:
+    message: "Name?"
+    input: {type: text, save_as: name}
+    branches:
+      - when: {var: name, op: eq, value: "nobody"}
+        next: broken
+      - next: greet
+  broken:
+    message: "Hi {{nickname}}"
+  greet:
+    message: "Hi {{name}}"
+`)
+	var events []Event
+	ce := NewConversationEngine(b, llm.NewNoopProvider(),
+		WithMissing(tmpl.Missing{Mode: tmpl.MissingError}),
+		WithObserver(func(e Event) {
+			events = append(events, e)
+		}))
+	ctx := context.Background()
+	id := ce.SessionID()
+
+	if _, err := ce.Start(ctx, id); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if _, err := ce.Step(ctx, id, "nobody"); err == nil {
+		t.Fatal("Step: expected a render error")
+	}
+	if len(events) != 1 {
+		t.Fatalf("events after the failed step: got %+v, want only the visit to start", events)
+	}
+
+	if _, err := ce.Step(ctx, id, "Ada"); err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+	want := []Event{
+		{Kind: EventVisit, Node: "start"},
+		{Kind: EventBranch, Node: "start", Branch: 1},
+		{Kind: EventVisit, Node: "greet"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("events: got %+v, want %+v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event %d: got %+v, want %+v", i, events[i], want[i])
+		}
+	}
+}
+
+func TestMatchesConditionUsesCompiledPattern(t *testing.T) {
+	b := loadTestBot(t, `
+bot:
+  name: codes
+flows:
+  start:
+    message: "Code?"
+    input: {type: text, save_as: code}
+    branches:
+      - when: {var: code, op: matches, value: "^[A-Z]{2}[0-9]{3}$"}
+        next: valid
+      - next: invalid
+  valid:
+    message: "Valid"
+  invalid:
+    message: "Invalid"
+`)
+	cond := b.Flows["start"].Branches[0].When
+	// The pattern compiled at load is used, not the source
+	cond.Value = "("
+
+	ok, err := EvaluateCondition(cond, map[string]string{"code": "AB123"})
+	if err != nil || !ok {
+		t.Errorf("EvaluateCondition(AB123) = %v, %v; want a match", ok, err)
+	}
+	ok, err = EvaluateCondition(cond, map[string]string{"code": "ab123"})
+	if err != nil || ok {
+		t.Errorf("EvaluateCondition(ab123) = %v, %v; want no match", ok, err)
+	}
+}
@@ -2,6 +2,8 @@ package engine
 
 import (
 	"fmt"
+
+	"chatbot-go/internal/bot"
 )
 
 // Transition moves the session to a new node
@@ -15,18 +17,119 @@ func (e *Engine) Transition(nextNode string) error {
 	}
 
 	e.session.CurrentNode = nextNode
+	e.session.Retries = 0
 	return nil
 }
 
-// IsTerminal checks if the current node is terminal (no next node and no intents)
-func (e *Engine) IsTerminal() (bool, error) {
+// Jump moves the session to a node outside the normal flow, leaving any
+// active sub-flows
+func (e *Engine) Jump(nextNode string) error {
+	if err := e.Transition(nextNode); err != nil {
+		return err
+	}
+	e.session.Stack = nil
+	return nil
+}
+
+// Restart returns the session to the start node with no variables or
+// active sub-flows; history is kept
+func (e *Engine) Restart() error {
+	if err := e.Jump("start"); err != nil {
+		return err
+	}
+	e.session.Variables = make(map[string]string)
+	return nil
+}
+
+// Advance moves the session past the current node, following the first
+// branch whose condition holds and falling back to the node's next. It
+// returns the index of the branch taken, or -1 if none was.
+func (e *Engine) Advance() (int, error) {
 	node, err := e.GetCurrentNode()
+	if err != nil {
+		return -1, err
+	}
+
+	branch, next, err := e.ResolveBranch(node)
+	if err != nil {
+		return -1, err
+	}
+	if next == "" {
+		return branch, nil
+	}
+	return branch, e.Transition(next)
+}
+
+// ResolveBranch returns the index of the first branch of the node whose
+// condition holds and the node it leads to; the index is -1 when the node
+// falls back to its next
+func (e *Engine) ResolveBranch(node *bot.Node) (int, string, error) {
+	for i, branch := range node.Branches {
+		if branch.When == nil {
+			return i, branch.Next, nil
+		}
+		ok, err := EvaluateCondition(branch.When, e.session.Variables)
+		if err != nil {
+			return -1, "", ErrInvalidTransition(fmt.Sprintf("branch %d: %v", i+1, err))
+		}
+		if ok {
+			return i, branch.Next, nil
+		}
+	}
+
+	if len(node.Branches) > 0 && node.Next == "" {
+		return -1, "", ErrInvalidTransition(fmt.Sprintf("no branch matched in node '%s'", e.session.CurrentNode))
+	}
+	return -1, node.Next, nil
+}
+
+// Call pushes a frame for the given call and enters the sub-flow's start node
+func (e *Engine) Call(call *bot.Call) error {
+	subflow, exists := e.bot.Subflows[call.Flow]
+	if !exists {
+		return ErrInvalidTransition(fmt.Sprintf("sub-flow '%s' does not exist", call.Flow))
+	}
+
+	if err := e.Transition(subflow.Start); err != nil {
+		return err
+	}
+	e.session.Stack = append(e.session.Stack, Frame{Flow: call.Flow, Return: call.Return})
+	return nil
+}
+
+// Return pops the innermost sub-flow frame and resumes at its return node
+func (e *Engine) Return() error {
+	if len(e.session.Stack) == 0 {
+		return ErrInvalidTransition("no sub-flow to return from")
+	}
+
+	frame := e.session.Stack[len(e.session.Stack)-1]
+	if err := e.Transition(frame.Return); err != nil {
+		return err
+	}
+	e.session.Stack = e.session.Stack[:len(e.session.Stack)-1]
+	return nil
+}
+
+// AtFlowEnd checks if the current node ends the conversation or the active
+// sub-flow
+func (e *Engine) AtFlowEnd() (bool, error) {
+	node, err := e.GetCurrentNode()
+	if err != nil {
+		return false, err
+	}
+
+	return node.EndsFlow(), nil
+}
+
+// IsTerminal checks if the current node is terminal (flow end with no sub-flow to return from)
+func (e *Engine) IsTerminal() (bool, error) {
+	atEnd, err := e.AtFlowEnd()
 	if err != nil {
 		return false, err
 	}
 
-	// Terminal if no next node and no intents (or empty intents)
-	return node.Next == "" && len(node.Intents) == 0, nil
+	return atEnd && len(e.session.Stack) == 0, nil
 }
 
 // ErrInvalidTransition represents an invalid state transition error
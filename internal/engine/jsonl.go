@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// jsonlInput is one line of user input in JSON-lines mode: {"text": "..."}
+// sends an utterance and {"command": "back"} undoes the previous turn
+type jsonlInput struct {
+	Text    string `json:"text"`
+	Command string `json:"command,omitempty"`
+}
+
+// jsonlOutput is one line of bot output in JSON-lines mode
+type jsonlOutput struct {
+	SessionID string        `json:"session_id"`
+	Node      string        `json:"node"`
+	Messages  []string      `json:"messages"`
+	Intents   []jsonlIntent `json:"intents"`
+	Terminal  bool          `json:"terminal"`
+}
+
+// jsonlError is written instead of a response when an input line is rejected
+type jsonlError struct {
+	Error string `json:"error"`
+}
+
+// jsonlIntent describes an intent the user can choose next
+type jsonlIntent struct {
+	Name     string   `json:"name"`
+	Examples []string `json:"examples,omitempty"`
+}
+
+// RunJSONL drives the session with one JSON object per line: user input is
+// read from r and each bot response is written to w. It returns when a
+// terminal node is reached or r is exhausted.
+func (ce *ConversationEngine) RunJSONL(ctx context.Context, r io.Reader, w io.Writer) error {
+	sessionID := ce.SessionID()
+	encoder := json.NewEncoder(w)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	resp, err := ce.Start(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if err := encoder.Encode(newJSONLOutput(resp)); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		if resp.Terminal {
+			return nil
+		}
+
+		// Read lines until one is taken as a turn; rejected lines and failed
+		// turns are answered with an error and leave the session unchanged
+		for {
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
+					return fmt.Errorf("failed to read input: %w", err)
+				}
+				return nil
+			}
+
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			var in jsonlInput
+			problem := ""
+			if err := json.Unmarshal([]byte(line), &in); err != nil {
+				problem = fmt.Sprintf("invalid input: %v", err)
+			} else if in.Command != "" && in.Command != "back" {
+				problem = fmt.Sprintf("unknown command: %s", in.Command)
+			} else {
+				var next Response
+				if in.Command == "back" {
+					next, err = ce.Back(ctx, sessionID)
+				} else {
+					next, err = ce.Step(ctx, sessionID, in.Text)
+				}
+				if err == nil {
+					resp = next
+					break
+				}
+				if ctx.Err() != nil {
+					return err
+				}
+				problem = err.Error()
+			}
+			if err := encoder.Encode(jsonlError{Error: problem}); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+		}
+	}
+}
+
+// newJSONLOutput converts a response for JSON-lines output
+func newJSONLOutput(resp Response) jsonlOutput {
+	intents := make([]jsonlIntent, len(resp.Intents))
+	for i, intent := range resp.Intents {
+		intents[i] = jsonlIntent{Name: intent.Name, Examples: intent.Examples}
+	}
+	return jsonlOutput{
+		SessionID: resp.SessionID,
+		Node:      resp.Node,
+		Messages:  resp.Messages,
+		Intents:   intents,
+		Terminal:  resp.Terminal,
+	}
+}
@@ -0,0 +1,210 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"chatbot-go/internal/bot"
+)
+
+// Manager owns the sessions of one bot. It is safe for concurrent use: the
+// bot is shared read-only and turns on the same session are serialized.
+type Manager struct {
+	bot   *bot.Bot
+	store SessionStore
+	ttl   time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*managedSession
+	deleting map[string]int // pending store deletions by session ID
+}
+
+// managedSession is a live session with its own lock
+type managedSession struct {
+	mu       sync.Mutex
+	engine   *Engine
+	lastUsed time.Time
+	deleted  bool
+}
+
+// NewManager creates a session manager; sessions idle for longer than ttl
+// are removed by ExpireIdle, and a zero ttl keeps them forever
+func NewManager(b *bot.Bot, store SessionStore, ttl time.Duration) *Manager {
+	return &Manager{
+		bot:      b,
+		store:    store,
+		ttl:      ttl,
+		sessions: make(map[string]*managedSession),
+		deleting: make(map[string]int),
+	}
+}
+
+// Create starts a new session at the bot's start node and returns its ID
+func (m *Manager) Create() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := newSessionID()
+	if _, exists := m.sessions[id]; exists {
+		return "", fmt.Errorf("session ID collision: %s", id)
+	}
+	m.sessions[id] = m.track(NewSession(id, m.bot.Name))
+	return id, nil
+}
+
+// Open makes the session with the given ID live, resuming it from the store
+// if it was saved before and starting it fresh otherwise
+func (m *Manager) Open(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.sessions[id]; exists {
+		return nil
+	}
+
+	session, err := m.load(id)
+	if _, ok := err.(ErrSessionNotFound); ok {
+		session, err = NewSession(id, m.bot.Name), nil
+	}
+	if err != nil {
+		return err
+	}
+	m.sessions[id] = m.track(session)
+	return nil
+}
+
+// Get returns a copy of the session with the given ID
+func (m *Manager) Get(id string) (*Session, error) {
+	eng, release, err := m.acquire(id)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return eng.GetSession().Clone(), nil
+}
+
+// Delete removes a session from memory and from the store, waiting for any
+// turn in progress on it to finish. Other sessions are not held up: the
+// manager lock is only taken to mark the session as being deleted, which
+// stops turns from loading it back from the store in the meantime.
+func (m *Manager) Delete(id string) error {
+	m.mu.Lock()
+	ms, exists := m.sessions[id]
+	delete(m.sessions, id)
+	m.deleting[id]++
+	m.mu.Unlock()
+	defer m.deleted(id)
+
+	if exists {
+		ms.mu.Lock()
+		ms.deleted = true
+		ms.mu.Unlock()
+	}
+
+	if err := m.store.Delete(id); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// ExpireIdle deletes sessions that have not been used within the ttl and
+// returns their IDs; sessions with a turn in progress are skipped
+func (m *Manager) ExpireIdle() ([]string, error) {
+	if m.ttl <= 0 {
+		return nil, nil
+	}
+
+	cutoff := time.Now().Add(-m.ttl)
+	var expired []string
+
+	m.mu.Lock()
+	for id, ms := range m.sessions {
+		if !ms.mu.TryLock() {
+			continue
+		}
+		if ms.lastUsed.Before(cutoff) {
+			ms.deleted = true
+			delete(m.sessions, id)
+			m.deleting[id]++
+			expired = append(expired, id)
+		}
+		ms.mu.Unlock()
+	}
+	m.mu.Unlock()
+	defer m.deleted(expired...)
+
+	sort.Strings(expired)
+	for _, id := range expired {
+		if err := m.store.Delete(id); err != nil {
+			return expired, fmt.Errorf("failed to delete expired session '%s': %w", id, err)
+		}
+	}
+	return expired, nil
+}
+
+// deleted clears the marks set while sessions were removed from the store
+func (m *Manager) deleted(ids ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		if m.deleting[id]--; m.deleting[id] <= 0 {
+			delete(m.deleting, id)
+		}
+	}
+}
+
+// acquire locks the session with the given ID for a turn, loading it from
+// the store if it is not live; the returned function releases the lock
+func (m *Manager) acquire(id string) (*Engine, func(), error) {
+	m.mu.Lock()
+	ms, exists := m.sessions[id]
+	if !exists {
+		session, err := m.load(id)
+		if err != nil {
+			m.mu.Unlock()
+			return nil, nil, err
+		}
+		ms = m.track(session)
+		m.sessions[id] = ms
+	}
+	m.mu.Unlock()
+
+	ms.mu.Lock()
+	if ms.deleted {
+		ms.mu.Unlock()
+		return nil, nil, ErrSessionNotFound(id)
+	}
+	ms.lastUsed = time.Now()
+	return ms.engine, ms.mu.Unlock, nil
+}
+
+// load reads a saved session and checks it still fits the bot; sessions
+// being deleted are reported as not found
+func (m *Manager) load(id string) (*Session, error) {
+	if m.deleting[id] > 0 {
+		return nil, ErrSessionNotFound(id)
+	}
+	session, err := m.store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Bot != m.bot.Name {
+		return nil, fmt.Errorf("session '%s' belongs to bot '%s', not '%s'", id, session.Bot, m.bot.Name)
+	}
+	if _, exists := m.bot.Flows[session.CurrentNode]; !exists {
+		return nil, fmt.Errorf("session '%s' is at node '%s' which no longer exists", id, session.CurrentNode)
+	}
+	return session, nil
+}
+
+// track wraps a session for management
+func (m *Manager) track(session *Session) *managedSession {
+	return &managedSession{
+		engine:   NewEngineWithSession(m.bot, session),
+		lastUsed: time.Now(),
+	}
+}
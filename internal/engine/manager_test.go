@@ -0,0 +1,217 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"chatbot-go/internal/llm"
+)
+
+const managerTestBot = `
+bot:
+  name: counter
+flows:
+  start:
+    message: "Say something"
+    input: {type: text, save_as: said}
+    next: start
+`
+
+// newManagerTestEngine creates an engine whose sessions expire almost at once
+func newManagerTestEngine(t *testing.T, store SessionStore) *ConversationEngine {
+	t.Helper()
+	b := loadTestBot(t, managerTestBot)
+	return NewConversationEngine(b, llm.NewNoopProvider(),
+		WithStore(store),
+		WithSessionTTL(time.Nanosecond))
+}
+
+// isGone reports whether err says the session no longer exists
+func isGone(err error) bool {
+	var notFound ErrSessionNotFound
+	return errors.As(err, &notFound)
+}
+
+func TestManagerConcurrentSessions(t *testing.T) {
+	ce := newManagerTestEngine(t, NewMemoryStore())
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				id, err := ce.NewSession()
+				if err != nil {
+					t.Errorf("NewSession: %v", err)
+					return
+				}
+				for turn := 0; turn < 3; turn++ {
+					if _, err := ce.Step(ctx, id, fmt.Sprintf("%d-%d", worker, turn)); err != nil && !isGone(err) {
+						t.Errorf("Step: %v", err)
+					}
+					if _, err := ce.Session(id); err != nil && !isGone(err) {
+						t.Errorf("Session: %v", err)
+					}
+				}
+				if i%2 == 0 {
+					if err := ce.DeleteSession(id); err != nil {
+						t.Errorf("DeleteSession: %v", err)
+					}
+				}
+			}
+		}(worker)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			if _, err := ce.ExpireSessions(); err != nil {
+				t.Errorf("ExpireSessions: %v", err)
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+// slowDeleteStore holds up Delete until it is told to go on, widening the
+// window in which a turn can race a deletion
+type slowDeleteStore struct {
+	*MemoryStore
+	deleting chan struct{}
+	proceed  chan struct{}
+}
+
+func (s *slowDeleteStore) Delete(id string) error {
+	close(s.deleting)
+	<-s.proceed
+	return s.MemoryStore.Delete(id)
+}
+
+func TestManagerDeleteRacingStep(t *testing.T) {
+	store := &slowDeleteStore{
+		MemoryStore: NewMemoryStore(),
+		deleting:    make(chan struct{}),
+		proceed:     make(chan struct{}),
+	}
+	ce := newManagerTestEngine(t, store)
+	ctx := context.Background()
+
+	id, err := ce.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ce.Step(ctx, id, "first"); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted := make(chan error)
+	go func() {
+		deleted <- ce.DeleteSession(id)
+	}()
+	<-store.deleting
+
+	stepped := make(chan error)
+	go func() {
+		_, err := ce.Step(ctx, id, "again")
+		stepped <- err
+	}()
+	// Give the step a chance to run while the store entry still exists
+	time.Sleep(20 * time.Millisecond)
+	close(store.proceed)
+
+	if err := <-deleted; err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+	if err := <-stepped; !isGone(err) {
+		t.Errorf("Step during Delete: got %v, want session not found", err)
+	}
+	if _, err := store.Load(id); !isGone(err) {
+		t.Errorf("session is still stored after it was deleted (err %v)", err)
+	}
+	if _, err := ce.Session(id); !isGone(err) {
+		t.Errorf("session can still be read after it was deleted (err %v)", err)
+	}
+}
+
+// slowSaveStore holds up saving one session until it is told to go on, so a
+// turn on that session stays in progress
+type slowSaveStore struct {
+	*MemoryStore
+	slow    string
+	saving  chan struct{}
+	proceed chan struct{}
+}
+
+func (s *slowSaveStore) Save(session *Session) error {
+	if session.ID == s.slow {
+		close(s.saving)
+		<-s.proceed
+	}
+	return s.MemoryStore.Save(session)
+}
+
+func TestManagerDeleteDoesNotBlockOtherSessions(t *testing.T) {
+	store := &slowSaveStore{
+		MemoryStore: NewMemoryStore(),
+		saving:      make(chan struct{}),
+		proceed:     make(chan struct{}),
+	}
+	ce := newManagerTestEngine(t, store)
+	ctx := context.Background()
+
+	busy, err := ce.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ce.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.slow = busy
+
+	// Leave a turn in progress on busy and delete it meanwhile
+	stepped := make(chan error)
+	go func() {
+		_, err := ce.Step(ctx, busy, "slow")
+		stepped <- err
+	}()
+	<-store.saving
+	deleted := make(chan error)
+	go func() {
+		deleted <- ce.DeleteSession(busy)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	done := make(chan error)
+	go func() {
+		_, err := ce.Step(ctx, other, "hello")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Step on another session: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Step on another session waited for a deletion in progress")
+		defer func() { <-done }()
+	}
+
+	close(store.proceed)
+	if err := <-stepped; err != nil {
+		t.Errorf("Step on the deleted session: %v", err)
+	}
+	if err := <-deleted; err != nil {
+		t.Errorf("DeleteSession: %v", err)
+	}
+	if _, err := ce.Session(busy); !isGone(err) {
+		t.Errorf("deleted session can still be read (err %v)", err)
+	}
+}
@@ -0,0 +1,71 @@
+package engine
+
+import "context"
+
+// EventKind identifies what an Event reports
+type EventKind string
+
+const (
+	// EventVisit reports that the session arrived at Node
+	EventVisit EventKind = "visit"
+
+	// EventIntent reports that Router matched input at Node to its intent
+	// Intent
+	EventIntent EventKind = "intent"
+
+	// EventGlobal reports that input at Node matched the global intent Intent
+	EventGlobal EventKind = "global"
+
+	// EventMiss reports that input at Node matched none of its intents
+	EventMiss EventKind = "miss"
+
+	// EventBranch reports that Node moved on through branch Branch, or
+	// through its next when Branch is -1
+	EventBranch EventKind = "branch"
+)
+
+// Event is something that happened while a step ran
+type Event struct {
+	Kind   EventKind
+	Node   string
+	Intent string
+	Router string // rule or llm
+	Branch int
+}
+
+// ObserveFunc receives the events of every step, in the order they happen
+type ObserveFunc func(Event)
+
+// eventsKey is the context key for the events buffered during a turn
+type eventsKey struct{}
+
+// observe adds an event to the record of the running turn and holds it for
+// the observer until the turn is saved
+func (ce *ConversationEngine) observe(ctx context.Context, e Event) {
+	if events, ok := ctx.Value(eventsKey{}).(*[]Event); ok {
+		*events = append(*events, e)
+	}
+	if rec := recordFrom(ctx); rec != nil {
+		rec.apply(e)
+	}
+}
+
+// bufferEvents returns a context that collects the events of a turn when an
+// observer is configured
+func (ce *ConversationEngine) bufferEvents(ctx context.Context) (context.Context, *[]Event) {
+	if ce.observer == nil {
+		return ctx, nil
+	}
+	events := &[]Event{}
+	return context.WithValue(ctx, eventsKey{}, events), events
+}
+
+// emitEvents reports the events of a saved turn to the observer
+func (ce *ConversationEngine) emitEvents(events *[]Event) {
+	if events == nil {
+		return
+	}
+	for _, e := range *events {
+		ce.observer(e)
+	}
+}
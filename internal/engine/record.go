@@ -0,0 +1,198 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"chatbot-go/internal/llm"
+)
+
+// TurnRecord is everything that happened in one Start, Step or Back of a
+// session, as written to a transcript
+type TurnRecord struct {
+	Session  string            `json:"session"`
+	Bot      string            `json:"bot"`
+	Time     time.Time         `json:"time"`
+	Action   string            `json:"action"` // start, step or back
+	Input    string            `json:"input,omitempty"`
+	Node     string            `json:"node"` // node before the turn
+	Route    *RouteRecord      `json:"route,omitempty"`
+	Branch   *int              `json:"branch,omitempty"` // branch taken, -1 for the node's next
+	LLM      []LLMCall         `json:"llm,omitempty"`
+	Before   map[string]string `json:"variables_before"`
+	Stack    []Frame           `json:"stack_before,omitempty"`   // sub-flow calls active before the turn
+	History  []Turn            `json:"history_before,omitempty"` // turns taken before this one, for back
+	Retries  int               `json:"retries_before,omitempty"` // consecutive rejected or unmatched inputs before the turn
+	After    map[string]string `json:"variables_after"`
+	Visited  []string          `json:"visited,omitempty"` // nodes entered during the turn, in order
+	Next     string            `json:"next"`              // node after the turn
+	Messages []string          `json:"messages,omitempty"`
+	Terminal bool              `json:"terminal,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// RouteRecord is the routing decision for a turn's input
+type RouteRecord struct {
+	Intent string `json:"intent,omitempty"` // empty when nothing matched
+	Router string `json:"router"`           // rule, llm, or none when nothing matched
+	Global bool   `json:"global,omitempty"`
+}
+
+// LLMCall is one request to the LLM provider and its response
+type LLMCall struct {
+	Method   string `json:"method"` // classify, extract or generate
+	Request  string `json:"request"`
+	Response string `json:"response,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Recorder receives a record of every turn
+type Recorder interface {
+	Record(turn TurnRecord) error
+}
+
+// WithRecorder records every turn of every session, including LLM calls,
+// to the given recorder
+func WithRecorder(recorder Recorder) Option {
+	return func(ce *ConversationEngine) {
+		ce.recorder = recorder
+	}
+}
+
+// recordKey is the context key for the TurnRecord of the running turn
+type recordKey struct{}
+
+// recordFrom returns the TurnRecord carried by ctx, if any
+func recordFrom(ctx context.Context) *TurnRecord {
+	rec, _ := ctx.Value(recordKey{}).(*TurnRecord)
+	return rec
+}
+
+// beginRecord starts recording a turn when a recorder is configured and
+// returns a context carrying the record
+func (ce *ConversationEngine) beginRecord(ctx context.Context, eng *Engine, action, input string) (context.Context, *TurnRecord) {
+	if ce.recorder == nil {
+		return ctx, nil
+	}
+	session := eng.GetSession()
+	rec := &TurnRecord{
+		Session: session.ID,
+		Bot:     session.Bot,
+		Time:    time.Now().UTC(),
+		Action:  action,
+		Input:   input,
+		Node:    session.CurrentNode,
+		Before:  copyVariables(session.Variables),
+		Stack:   append([]Frame(nil), session.Stack...),
+		History: session.Clone().History,
+		Retries: session.Retries,
+	}
+	return context.WithValue(ctx, recordKey{}, rec), rec
+}
+
+// finishRecord completes a turn's record with its outcome and hands it to
+// the recorder
+func (ce *ConversationEngine) finishRecord(rec *TurnRecord, eng *Engine, resp Response, stepErr error) error {
+	if rec == nil {
+		return nil
+	}
+	session := eng.GetSession()
+	rec.After = copyVariables(session.Variables)
+	rec.Next = session.CurrentNode
+	rec.Messages = resp.Messages
+	rec.Terminal = resp.Terminal
+	if stepErr != nil {
+		rec.Error = stepErr.Error()
+	}
+	if err := ce.recorder.Record(*rec); err != nil {
+		return fmt.Errorf("failed to record turn: %w", err)
+	}
+	return nil
+}
+
+// apply adds an engine event to the record
+func (rec *TurnRecord) apply(e Event) {
+	switch e.Kind {
+	case EventVisit:
+		rec.Visited = append(rec.Visited, e.Node)
+	case EventIntent:
+		rec.Route = &RouteRecord{Intent: e.Intent, Router: e.Router}
+	case EventGlobal:
+		rec.Route = &RouteRecord{Intent: e.Intent, Router: e.Router, Global: true}
+	case EventMiss:
+		rec.Route = &RouteRecord{Router: "none"}
+	case EventBranch:
+		branch := e.Branch
+		rec.Branch = &branch
+	}
+}
+
+// recordingProvider adds every call to the LLM provider to the record of
+// the running turn
+type recordingProvider struct {
+	provider llm.Provider
+}
+
+// recordingStreamer is a recordingProvider for a provider that streams
+type recordingStreamer struct {
+	recordingProvider
+	streamer llm.StreamingProvider
+}
+
+// recordLLM wraps a provider so its calls are recorded, keeping its
+// streaming support
+func recordLLM(provider llm.Provider) llm.Provider {
+	recording := recordingProvider{provider: provider}
+	if streamer, ok := provider.(llm.StreamingProvider); ok {
+		return &recordingStreamer{recordingProvider: recording, streamer: streamer}
+	}
+	return &recording
+}
+
+// ClassifyIntent classifies input with the wrapped provider
+func (p *recordingProvider) ClassifyIntent(ctx context.Context, input string, intents []llm.Intent) (string, error) {
+	intent, err := p.provider.ClassifyIntent(ctx, input, intents)
+	addLLMCall(ctx, "classify", input, intent, err)
+	return intent, err
+}
+
+// ExtractEntities extracts entities with the wrapped provider
+func (p *recordingProvider) ExtractEntities(ctx context.Context, input string, schema map[string]string) (map[string]string, error) {
+	entities, err := p.provider.ExtractEntities(ctx, input, schema)
+	var response string
+	if entities != nil {
+		encoded, _ := json.Marshal(entities)
+		response = string(encoded)
+	}
+	addLLMCall(ctx, "extract", input, response, err)
+	return entities, err
+}
+
+// GenerateText generates text with the wrapped provider
+func (p *recordingProvider) GenerateText(ctx context.Context, prompt llm.Prompt) (string, error) {
+	text, err := p.provider.GenerateText(ctx, prompt)
+	addLLMCall(ctx, "generate", prompt.Text, text, err)
+	return text, err
+}
+
+// StreamText streams text from the wrapped provider
+func (p *recordingStreamer) StreamText(ctx context.Context, prompt llm.Prompt, onChunk func(chunk string)) (string, error) {
+	text, err := p.streamer.StreamText(ctx, prompt, onChunk)
+	addLLMCall(ctx, "generate", prompt.Text, text, err)
+	return text, err
+}
+
+// addLLMCall adds an LLM call to the record carried by ctx, if any
+func addLLMCall(ctx context.Context, method, request, response string, err error) {
+	rec := recordFrom(ctx)
+	if rec == nil {
+		return
+	}
+	call := LLMCall{Method: method, Request: request, Response: response}
+	if err != nil {
+		call.Error = err.Error()
+	}
+	rec.LLM = append(rec.LLM, call)
+}
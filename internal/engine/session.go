@@ -17,11 +17,47 @@ func (e *Engine) GetVariable(key string) (string, bool) {
 	return val, exists
 }
 
-// AddTurn adds a turn to the conversation history
-func (e *Engine) AddTurn(node, userInput, response string) {
+// restore puts the session back into an earlier state captured with Clone
+func (e *Engine) restore(saved *Session) {
+	*e.session = *saved
+}
+
+// Snapshot copies the current node, variables and call stack
+func (e *Engine) Snapshot() Snapshot {
+	return Snapshot{
+		Node:      e.session.CurrentNode,
+		Variables: copyVariables(e.session.Variables),
+		Stack:     append([]Frame(nil), e.session.Stack...),
+	}
+}
+
+// AddTurn adds a turn to the conversation history, keeping the state the
+// session was in before the turn
+func (e *Engine) AddTurn(before Snapshot, userInput, response string) {
 	e.session.History = append(e.session.History, Turn{
-		Node:      node,
+		Node:      before.Node,
 		UserInput: userInput,
 		Response:  response,
+		Variables: before.Variables,
+		Stack:     before.Stack,
 	})
 }
+
+// Back undoes the most recent turn: the session returns to the node where
+// the turn was taken, variables and sub-flow calls are rolled back to their
+// state before it, and the turn is dropped from the history
+func (e *Engine) Back() error {
+	if len(e.session.History) == 0 {
+		return ErrNoHistory{}
+	}
+
+	last := e.session.History[len(e.session.History)-1]
+	if err := e.Transition(last.Node); err != nil {
+		return err
+	}
+
+	e.session.Variables = copyVariables(last.Variables)
+	e.session.Stack = append([]Frame(nil), last.Stack...)
+	e.session.History = e.session.History[:len(e.session.History)-1]
+	return nil
+}
@@ -0,0 +1,168 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+// validSessionID restricts session IDs to characters safe for file names
+var validSessionID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// SessionStore persists sessions so conversations can be resumed
+type SessionStore interface {
+	// Load returns the session with the given ID, or ErrSessionNotFound
+	Load(id string) (*Session, error)
+
+	// Save stores the session under its ID, replacing any previous version
+	Save(session *Session) error
+
+	// Delete removes the session with the given ID; missing sessions are ignored
+	Delete(id string) error
+}
+
+// MemoryStore keeps sessions in memory; useful for tests and single runs
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[string][]byte
+}
+
+// NewMemoryStore creates an empty in-memory session store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[string][]byte),
+	}
+}
+
+// Load returns a copy of the stored session
+func (m *MemoryStore) Load(id string) (*Session, error) {
+	m.mu.Lock()
+	data, exists := m.sessions[id]
+	m.mu.Unlock()
+	if !exists {
+		return nil, ErrSessionNotFound(id)
+	}
+	return decodeSession(data)
+}
+
+// Save stores a copy of the session
+func (m *MemoryStore) Save(session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	m.mu.Lock()
+	m.sessions[session.ID] = data
+	m.mu.Unlock()
+	return nil
+}
+
+// Delete removes the session
+func (m *MemoryStore) Delete(id string) error {
+	m.mu.Lock()
+	delete(m.sessions, id)
+	m.mu.Unlock()
+	return nil
+}
+
+// FileStore keeps each session as a JSON file named after its ID
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a session store backed by the given directory
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{
+		dir: dir,
+	}
+}
+
+// Load reads the session file
+func (f *FileStore) Load(id string) (*Session, error) {
+	path, err := f.path(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrSessionNotFound(id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+	return decodeSession(data)
+}
+
+// Save writes the session file atomically
+func (f *FileStore) Save(session *Session) error {
+	path, err := f.path(session.ID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	if err := os.MkdirAll(f.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(f.dir, session.ID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write session file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write session file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write session file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write session file: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the session file
+func (f *FileStore) Delete(id string) error {
+	path, err := f.path(id)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete session file: %w", err)
+	}
+	return nil
+}
+
+// path returns the file holding the given session
+func (f *FileStore) path(id string) (string, error) {
+	if !validSessionID.MatchString(id) {
+		return "", fmt.Errorf("invalid session ID '%s': use letters, digits, '-' and '_'", id)
+	}
+	return filepath.Join(f.dir, id+".json"), nil
+}
+
+// decodeSession parses a stored session
+func decodeSession(data []byte) (*Session, error) {
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	if session.Variables == nil {
+		session.Variables = make(map[string]string)
+	}
+	return &session, nil
+}
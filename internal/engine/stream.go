@@ -0,0 +1,38 @@
+package engine
+
+import "context"
+
+// StreamKind identifies what a piece of streamed output is
+type StreamKind string
+
+const (
+	// StreamMessage is a complete bot message
+	StreamMessage StreamKind = "message"
+
+	// StreamChunk is a fragment of LLM-generated text, sent ahead of the
+	// complete message it forms
+	StreamChunk StreamKind = "chunk"
+
+	// StreamAbort reports that generation failed after chunks were sent: no
+	// complete message follows, so the chunks should be discarded. The text
+	// is the reason.
+	StreamAbort StreamKind = "abort"
+)
+
+// StreamFunc receives bot output while a step is still running
+type StreamFunc func(kind StreamKind, text string)
+
+// streamKey is the context key for a StreamFunc
+type streamKey struct{}
+
+// WithStream returns a context that delivers bot output to fn as it is
+// produced by Start, Step or Back
+func WithStream(ctx context.Context, fn StreamFunc) context.Context {
+	return context.WithValue(ctx, streamKey{}, fn)
+}
+
+// streamFrom returns the StreamFunc carried by ctx, if any
+func streamFrom(ctx context.Context) StreamFunc {
+	fn, _ := ctx.Value(streamKey{}).(StreamFunc)
+	return fn
+}
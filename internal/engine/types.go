@@ -2,17 +2,34 @@ package engine
 
 import (
 	"chatbot-go/internal/bot"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 )
 
 // Session represents the current conversation session
 type Session struct {
-	CurrentNode string
-	Variables   map[string]string
-	History     []Turn
+	ID          string            `json:"id"`
+	Bot         string            `json:"bot"`
+	CurrentNode string            `json:"current_node"`
+	Variables   map[string]string `json:"variables"`
+	History     []Turn            `json:"history"`
+	Stack       []Frame           `json:"stack,omitempty"`
+	Retries     int               `json:"retries,omitempty"` // consecutive rejected or unmatched inputs at the current node
 }
 
-// GetVariables returns all variables (implements render.SessionView)
+// NewSession creates a session for the given bot positioned at its start node
+func NewSession(id, botName string) *Session {
+	return &Session{
+		ID:          id,
+		Bot:         botName,
+		CurrentNode: "start",
+		Variables:   make(map[string]string),
+		History:     []Turn{},
+	}
+}
+
+// GetVariables returns all variables
 func (s *Session) GetVariables() map[string]string {
 	if s.Variables == nil {
 		return make(map[string]string)
@@ -20,11 +37,60 @@ func (s *Session) GetVariables() map[string]string {
 	return s.Variables
 }
 
-// Turn represents a single turn in the conversation
+// Clone returns a deep copy of the session
+func (s *Session) Clone() *Session {
+	clone := *s
+	clone.Variables = copyVariables(s.Variables)
+	clone.Stack = append([]Frame(nil), s.Stack...)
+	clone.History = make([]Turn, len(s.History))
+	for i, turn := range s.History {
+		turn.Variables = copyVariables(turn.Variables)
+		turn.Stack = append([]Frame(nil), turn.Stack...)
+		clone.History[i] = turn
+	}
+	return &clone
+}
+
+// Path returns the nodes the conversation has visited, in order, ending at
+// the current node
+func (s *Session) Path() []string {
+	path := make([]string, 0, len(s.History)+1)
+	for _, turn := range s.History {
+		path = append(path, turn.Node)
+	}
+	return append(path, s.CurrentNode)
+}
+
+// copyVariables returns a copy of a variables map
+func copyVariables(vars map[string]string) map[string]string {
+	copied := make(map[string]string, len(vars))
+	for key, value := range vars {
+		copied[key] = value
+	}
+	return copied
+}
+
+// Turn represents a single turn in the conversation. Variables and Stack hold
+// the session state from before the turn so it can be undone.
 type Turn struct {
+	Node      string            `json:"node"`
+	UserInput string            `json:"user_input"`
+	Response  string            `json:"response"`
+	Variables map[string]string `json:"variables"`
+	Stack     []Frame           `json:"stack,omitempty"`
+}
+
+// Snapshot captures the session state at the start of a turn
+type Snapshot struct {
 	Node      string
-	UserInput string
-	Response  string
+	Variables map[string]string
+	Stack     []Frame
+}
+
+// Frame records an active sub-flow call
+type Frame struct {
+	Flow   string `json:"flow"`
+	Return string `json:"return"`
 }
 
 // Engine manages the conversation flow using FSM
@@ -35,13 +101,14 @@ type Engine struct {
 
 // NewEngine creates a new conversation engine
 func NewEngine(b *bot.Bot) *Engine {
+	return NewEngineWithSession(b, NewSession(newSessionID(), b.Name))
+}
+
+// NewEngineWithSession creates a conversation engine that continues an existing session
+func NewEngineWithSession(b *bot.Bot, session *Session) *Engine {
 	return &Engine{
-		bot: b,
-		session: &Session{
-			CurrentNode: "start",
-			Variables:   make(map[string]string),
-			History:     []Turn{},
-		},
+		bot:     b,
+		session: session,
 	}
 }
 
@@ -59,9 +126,47 @@ func (e *Engine) GetCurrentNode() (*bot.Node, error) {
 	return node, nil
 }
 
+// newSessionID generates a random session identifier
+func newSessionID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		panic(fmt.Sprintf("failed to generate session ID: %v", err))
+	}
+	return hex.EncodeToString(buf)
+}
+
 // ErrNodeNotFound represents a node not found error
 type ErrNodeNotFound string
 
 func (e ErrNodeNotFound) Error() string {
 	return fmt.Sprintf("node '%s' not found", string(e))
 }
+
+// ErrSessionNotFound represents an unknown session ID error
+type ErrSessionNotFound string
+
+func (e ErrSessionNotFound) Error() string {
+	return fmt.Sprintf("session '%s' not found", string(e))
+}
+
+// ErrTransitionLimit indicates a step followed more sub-flow calls and
+// returns than allowed, ending at the named node, which means the flow loops
+type ErrTransitionLimit string
+
+func (e ErrTransitionLimit) Error() string {
+	return fmt.Sprintf("exceeded %d sub-flow transitions at node '%s'", maxAutoTransitions, string(e))
+}
+
+// ErrConversationEnded indicates input was sent to a session at a terminal node
+type ErrConversationEnded struct{}
+
+func (e ErrConversationEnded) Error() string {
+	return "conversation has ended"
+}
+
+// ErrNoHistory indicates there is no previous turn to go back to
+type ErrNoHistory struct{}
+
+func (e ErrNoHistory) Error() string {
+	return "no previous turn to go back to"
+}
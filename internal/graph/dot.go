@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// dotShapes are the Graphviz attributes of each node kind
+var dotShapes = map[NodeKind]string{
+	KindStep:     `shape=box, style=rounded`,
+	KindInput:    `shape=parallelogram, style=filled, fillcolor="#dbeafe"`,
+	KindCall:     `shape=box, style="rounded,dashed"`,
+	KindTerminal: `shape=doublecircle, style=filled, fillcolor="#dcfce7"`,
+}
+
+// WriteDOT writes the graph in Graphviz DOT format, highlighting the nodes
+// and transitions of path if it is not empty
+func (g *Graph) WriteDOT(w io.Writer, name string, path []string) error {
+	h := newHighlight(path)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "digraph %s {\n", dotQuote(name))
+	out.WriteString("  rankdir=TB;\n")
+	out.WriteString("  node [fontname=\"Helvetica\"];\n")
+	out.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n\n")
+
+	for _, node := range g.Nodes {
+		attrs := dotShapes[node.Kind]
+		switch {
+		case h.nodes[node.Name]:
+			attrs += `, color="#dc2626", penwidth=3`
+		case node.Name == "start":
+			attrs += ", penwidth=2"
+		}
+		fmt.Fprintf(&out, "  %s [%s];\n", dotQuote(node.Name), attrs)
+	}
+	out.WriteString("\n")
+
+	for _, edge := range g.Edges {
+		var attrs []string
+		if edge.Label != "" {
+			attrs = append(attrs, "label="+dotQuote(edge.Label))
+		}
+		if h.edge(edge) {
+			attrs = append(attrs, `color="#dc2626"`, "penwidth=3")
+		}
+		fmt.Fprintf(&out, "  %s -> %s", dotQuote(edge.From), dotQuote(edge.To))
+		if len(attrs) > 0 {
+			fmt.Fprintf(&out, " [%s]", strings.Join(attrs, ", "))
+		}
+		out.WriteString(";\n")
+	}
+	out.WriteString("}\n")
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// dotQuote quotes an identifier or label for DOT
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
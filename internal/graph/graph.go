@@ -0,0 +1,205 @@
+package graph
+
+import (
+	"chatbot-go/internal/bot"
+	"fmt"
+	"sort"
+)
+
+// NodeKind classifies a node for styling
+type NodeKind string
+
+const (
+	// KindStep is a node that takes intents or any reply
+	KindStep NodeKind = "step"
+
+	// KindInput is a node that captures a typed value
+	KindInput NodeKind = "input"
+
+	// KindCall is a node that enters a sub-flow
+	KindCall NodeKind = "call"
+
+	// KindTerminal is a node that ends the conversation
+	KindTerminal NodeKind = "terminal"
+)
+
+// Node is a flow node in the graph
+type Node struct {
+	Name string
+	Kind NodeKind
+}
+
+// Edge is a possible transition, labelled with what triggers it
+type Edge struct {
+	From  string
+	To    string
+	Label string
+}
+
+// Graph is the flow of a bot as nodes and labelled edges
+type Graph struct {
+	Nodes []Node
+	Edges []Edge
+}
+
+// Build creates the graph of a bot's flows. Nodes are ordered as they are
+// reached from start, followed by any unreachable ones by name, and each
+// node's edges follow the order they are declared in.
+func Build(b *bot.Bot) *Graph {
+	inMainFlow := mainFlowNodes(b)
+	g := &Graph{}
+	for _, name := range nodeOrder(b) {
+		node := b.Flows[name]
+		kind := KindStep
+		switch {
+		case node.Input != nil:
+			kind = KindInput
+		case node.Call != nil:
+			kind = KindCall
+		case node.EndsFlow() && inMainFlow[name]:
+			kind = KindTerminal
+		}
+		g.Nodes = append(g.Nodes, Node{Name: name, Kind: kind})
+		g.Edges = append(g.Edges, nodeEdges(b, name, node)...)
+	}
+	return g
+}
+
+// nodeOrder lists the bot's nodes breadth-first from start, then the rest
+// by name
+func nodeOrder(b *bot.Bot) []string {
+	var order []string
+	seen := make(map[string]bool)
+	visit := func(start string) {
+		queue := []string{start}
+		seen[start] = true
+		for len(queue) > 0 {
+			name := queue[0]
+			queue = queue[1:]
+			order = append(order, name)
+			for _, edge := range nodeEdges(b, name, b.Flows[name]) {
+				if !seen[edge.To] && b.Flows[edge.To] != nil {
+					seen[edge.To] = true
+					queue = append(queue, edge.To)
+				}
+			}
+		}
+	}
+
+	if b.Flows["start"] != nil {
+		visit("start")
+	}
+	names := make([]string, 0, len(b.Flows))
+	for name, node := range b.Flows {
+		if node != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !seen[name] {
+			visit(name)
+		}
+	}
+	return order
+}
+
+// nodeEdges returns the labelled transitions out of a node
+func nodeEdges(b *bot.Bot, name string, node *bot.Node) []Edge {
+	var edges []Edge
+	add := func(to, label string) {
+		if _, exists := b.Flows[to]; exists && to != "" {
+			edges = append(edges, Edge{From: name, To: to, Label: label})
+		}
+	}
+
+	for _, intent := range node.Intents {
+		add(intent.Next, intent.Name)
+	}
+	for _, branch := range node.Branches {
+		if branch.When == nil {
+			add(branch.Next, "otherwise")
+		} else {
+			add(branch.Next, DescribeCondition(branch.When))
+		}
+	}
+	if node.Call != nil {
+		if subflow, exists := b.Subflows[node.Call.Flow]; exists {
+			add(subflow.Start, "call "+node.Call.Flow)
+		}
+		add(node.Call.Return, "return")
+	}
+	if node.Next != "" {
+		label := ""
+		if len(node.Branches) > 0 {
+			label = "otherwise"
+		}
+		add(node.Next, label)
+	}
+	if node.Input != nil && node.Input.Fallback != "" {
+		add(node.Input.Fallback, fmt.Sprintf("%d invalid", node.Input.MaxRetries))
+	}
+	if len(node.Intents) > 0 {
+		if fallback := b.FallbackFor(node); fallback.Next != "" {
+			add(fallback.Next, fmt.Sprintf("%d misses", fallback.MaxMisses))
+		}
+	}
+	return edges
+}
+
+// DescribeCondition writes a branch condition as a short label
+func DescribeCondition(cond *bot.Condition) string {
+	switch cond.Op {
+	case "exists", "missing":
+		return cond.Var + " " + cond.Op
+	case "":
+		return cond.Var + " eq " + cond.Value
+	default:
+		return cond.Var + " " + cond.Op + " " + cond.Value
+	}
+}
+
+// mainFlowNodes returns the nodes reachable from start without entering a
+// sub-flow; only there does reaching the end of the flow end the conversation
+func mainFlowNodes(b *bot.Bot) map[string]bool {
+	members := make(map[string]bool)
+	queue := []string{"start"}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		node := b.Flows[name]
+		if node == nil || members[name] {
+			continue
+		}
+		members[name] = true
+		for _, edge := range nodeEdges(b, name, node) {
+			if node.Call == nil || edge.To == node.Call.Return {
+				queue = append(queue, edge.To)
+			}
+		}
+	}
+	return members
+}
+
+// highlight marks the nodes and consecutive transitions of a path
+type highlight struct {
+	nodes map[string]bool
+	edges map[[2]string]bool
+}
+
+// newHighlight indexes a path of visited node names
+func newHighlight(path []string) highlight {
+	h := highlight{nodes: make(map[string]bool), edges: make(map[[2]string]bool)}
+	for i, name := range path {
+		h.nodes[name] = true
+		if i > 0 {
+			h.edges[[2]string{path[i-1], name}] = true
+		}
+	}
+	return h
+}
+
+// edge reports whether the path took a transition
+func (h highlight) edge(e Edge) bool {
+	return h.edges[[2]string{e.From, e.To}]
+}
@@ -0,0 +1,94 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMermaid writes the graph as a Mermaid flowchart, highlighting the
+// nodes and transitions of path if it is not empty
+func (g *Graph) WriteMermaid(w io.Writer, path []string) error {
+	h := newHighlight(path)
+	ids := mermaidIDs(g.Nodes)
+
+	var out strings.Builder
+	out.WriteString("flowchart TD\n")
+	for _, node := range g.Nodes {
+		label := mermaidLabel(node.Name)
+		id := ids[node.Name]
+		switch node.Kind {
+		case KindInput:
+			fmt.Fprintf(&out, "  %s[/%s/]\n", id, label)
+		case KindCall:
+			fmt.Fprintf(&out, "  %s[[%s]]\n", id, label)
+		case KindTerminal:
+			fmt.Fprintf(&out, "  %s((%s))\n", id, label)
+		default:
+			fmt.Fprintf(&out, "  %s(%s)\n", id, label)
+		}
+	}
+
+	var highlighted []int
+	for i, edge := range g.Edges {
+		if edge.Label == "" {
+			fmt.Fprintf(&out, "  %s --> %s\n", ids[edge.From], ids[edge.To])
+		} else {
+			fmt.Fprintf(&out, "  %s -->|%s| %s\n", ids[edge.From], mermaidLabel(edge.Label), ids[edge.To])
+		}
+		if h.edge(edge) {
+			highlighted = append(highlighted, i)
+		}
+	}
+
+	out.WriteString("\n")
+	out.WriteString("  classDef input fill:#dbeafe\n")
+	out.WriteString("  classDef terminal fill:#dcfce7\n")
+	out.WriteString("  classDef visited stroke:#dc2626,stroke-width:3px\n")
+	for _, node := range g.Nodes {
+		switch node.Kind {
+		case KindInput, KindTerminal:
+			fmt.Fprintf(&out, "  class %s %s\n", ids[node.Name], node.Kind)
+		}
+		if h.nodes[node.Name] {
+			fmt.Fprintf(&out, "  class %s visited\n", ids[node.Name])
+		}
+	}
+	for _, i := range highlighted {
+		fmt.Fprintf(&out, "  linkStyle %d stroke:#dc2626,stroke-width:3px\n", i)
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// mermaidIDs gives every node an identifier Mermaid accepts, keeping names
+// that are already valid
+func mermaidIDs(nodes []Node) map[string]string {
+	ids := make(map[string]string, len(nodes))
+	used := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		id := strings.Map(func(r rune) rune {
+			if r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+				return r
+			}
+			return '_'
+		}, node.Name)
+		// Mermaid reserves "end" and "graph" as keywords
+		if id == "end" || id == "graph" {
+			id += "_"
+		}
+		for used[id] {
+			id += "_"
+		}
+		used[id] = true
+		ids[node.Name] = id
+	}
+	return ids
+}
+
+// mermaidLabel quotes a label, escaping characters Mermaid would parse
+func mermaidLabel(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	return `"` + s + `"`
+}
@@ -89,6 +89,43 @@ func (o *OllamaProvider) GenerateText(
 	return o.callAPI(ctx, prompt.Text)
 }
 
+// StreamText uses Ollama to generate text, streaming fragments as they arrive
+func (o *OllamaProvider) StreamText(
+	ctx context.Context,
+	prompt Prompt,
+	onChunk func(chunk string),
+) (string, error) {
+	resp, err := o.post(ctx, prompt.Text, true)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	// Streaming responses are newline-delimited JSON objects, the last one
+	// marked done
+	var text bytes.Buffer
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var chunk struct {
+			Response string `json:"response"`
+			Done     bool   `json:"done"`
+		}
+		if err := decoder.Decode(&chunk); err == io.EOF {
+			return "", fmt.Errorf("ollama stream ended before the response was done")
+		} else if err != nil {
+			return "", err
+		}
+
+		if chunk.Response != "" {
+			text.WriteString(chunk.Response)
+			onChunk(chunk.Response)
+		}
+		if chunk.Done {
+			return text.String(), nil
+		}
+	}
+}
+
 // buildIntentClassificationPrompt builds a prompt for intent classification
 func (o *OllamaProvider) buildIntentClassificationPrompt(input string, intents []Intent) string {
 	var buf bytes.Buffer
@@ -117,42 +154,53 @@ func (o *OllamaProvider) buildEntityExtractionPrompt(input string, schema map[st
 
 // callAPI makes an HTTP request to Ollama API
 func (o *OllamaProvider) callAPI(ctx context.Context, prompt string) (string, error) {
+	resp, err := o.post(ctx, prompt, false)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Response string `json:"response"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+
+	return result.Response, nil
+}
+
+// post sends a generate request and returns the successful response
+func (o *OllamaProvider) post(ctx context.Context, prompt string, stream bool) (*http.Response, error) {
 	url := fmt.Sprintf("%s/api/generate", o.baseURL)
 
 	payload := map[string]interface{}{
 		"model":  o.model,
 		"prompt": prompt,
-		"stream": false,
+		"stream": stream,
 	}
 
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := o.client.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("ollama API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("ollama API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	var result struct {
-		Response string `json:"response"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
-	}
-
-	return result.Response, nil
+	return resp, nil
 }
@@ -25,6 +25,18 @@ type Provider interface {
 	) (string, error)
 }
 
+// StreamingProvider is implemented by providers that can deliver generated
+// text incrementally
+type StreamingProvider interface {
+	// StreamText generates text for a prompt, passing each fragment to
+	// onChunk as it arrives, and returns the complete text
+	StreamText(
+		ctx context.Context,
+		prompt Prompt,
+		onChunk func(chunk string),
+	) (string, error)
+}
+
 // Intent represents an intent with name and examples
 type Intent struct {
 	Name     string
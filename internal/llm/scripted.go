@@ -0,0 +1,214 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fixture scripts the responses of a ScriptedProvider. Each list is checked
+// in order and the first rule matching the input, or the prompt for
+// generate, answers the call.
+type Fixture struct {
+	Latency  time.Duration `yaml:"latency,omitempty"` // added to every call
+	Classify []FixtureRule `yaml:"classify,omitempty"`
+	Extract  []FixtureRule `yaml:"extract,omitempty"`
+	Generate []FixtureRule `yaml:"generate,omitempty"`
+}
+
+// FixtureRule matches a call and gives its response
+type FixtureRule struct {
+	Input    string            `yaml:"input,omitempty"`   // exact match, ignoring case and surrounding space
+	Pattern  string            `yaml:"pattern,omitempty"` // regular expression searched for
+	Intent   string            `yaml:"intent,omitempty"`
+	Entities map[string]string `yaml:"entities,omitempty"` // values may use $1 or ${name} for pattern groups
+	Text     string            `yaml:"text,omitempty"`     // may use pattern groups like entities
+	Error    string            `yaml:"error,omitempty"`    // fail the call with this message
+	Latency  time.Duration     `yaml:"latency,omitempty"`  // added to the fixture's latency
+
+	re *regexp.Regexp
+}
+
+// ScriptedProvider answers LLM calls from a fixture, so the LLM paths of a
+// bot can be exercised without a model
+type ScriptedProvider struct {
+	fixture Fixture
+}
+
+// ErrNoScriptedResponse indicates no fixture rule matched a call
+type ErrNoScriptedResponse struct {
+	Method string
+	Input  string
+}
+
+func (e ErrNoScriptedResponse) Error() string {
+	return fmt.Sprintf("no scripted %s response for %q", e.Method, e.Input)
+}
+
+// NewScriptedProvider creates a provider answering from a fixture
+func NewScriptedProvider(fixture Fixture) (*ScriptedProvider, error) {
+	lists := []struct {
+		method string
+		rules  []FixtureRule
+	}{
+		{"classify", fixture.Classify},
+		{"extract", fixture.Extract},
+		{"generate", fixture.Generate},
+	}
+	for _, list := range lists {
+		for i := range list.rules {
+			rule := &list.rules[i]
+			if (rule.Input == "") == (rule.Pattern == "") {
+				return nil, fmt.Errorf("%s rule %d needs either an input or a pattern", list.method, i+1)
+			}
+			if rule.Pattern == "" {
+				continue
+			}
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("%s rule %d has an invalid pattern: %w", list.method, i+1, err)
+			}
+			rule.re = re
+		}
+	}
+	return &ScriptedProvider{fixture: fixture}, nil
+}
+
+// LoadScriptedProvider creates a provider answering from a YAML fixture file
+func LoadScriptedProvider(path string) (*ScriptedProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read LLM fixture: %w", err)
+	}
+	var fixture Fixture
+	if err := yaml.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse LLM fixture: %w", err)
+	}
+	return NewScriptedProvider(fixture)
+}
+
+// ClassifyIntent returns the intent of the first matching classify rule,
+// which must be one of the intents offered
+func (s *ScriptedProvider) ClassifyIntent(
+	ctx context.Context,
+	input string,
+	intents []Intent,
+) (string, error) {
+	rule, _, err := s.answer(ctx, "classify", s.fixture.Classify, input)
+	if err != nil {
+		return "", err
+	}
+	for _, intent := range intents {
+		if intent.Name == rule.Intent {
+			return rule.Intent, nil
+		}
+	}
+	return "", fmt.Errorf("scripted classify intent '%s' for %q is not one of the offered intents", rule.Intent, input)
+}
+
+// ExtractEntities returns the entities of the first matching extract rule
+func (s *ScriptedProvider) ExtractEntities(
+	ctx context.Context,
+	input string,
+	schema map[string]string,
+) (map[string]string, error) {
+	rule, expand, err := s.answer(ctx, "extract", s.fixture.Extract, input)
+	if err != nil {
+		return nil, err
+	}
+	entities := make(map[string]string, len(rule.Entities))
+	for name, value := range rule.Entities {
+		entities[name] = expand(value)
+	}
+	return entities, nil
+}
+
+// GenerateText returns the text of the first generate rule matching the prompt
+func (s *ScriptedProvider) GenerateText(
+	ctx context.Context,
+	prompt Prompt,
+) (string, error) {
+	rule, expand, err := s.answer(ctx, "generate", s.fixture.Generate, prompt.Text)
+	if err != nil {
+		return "", err
+	}
+	return expand(rule.Text), nil
+}
+
+// StreamText returns the scripted text, passing it to onChunk word by word
+func (s *ScriptedProvider) StreamText(
+	ctx context.Context,
+	prompt Prompt,
+	onChunk func(chunk string),
+) (string, error) {
+	text, err := s.GenerateText(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	for _, word := range strings.SplitAfter(text, " ") {
+		if word != "" {
+			onChunk(word)
+		}
+	}
+	return text, nil
+}
+
+// answer finds the first rule matching the input, waits out its latency and
+// returns it with a function expanding pattern groups in its response
+func (s *ScriptedProvider) answer(ctx context.Context, method string, rules []FixtureRule, input string) (FixtureRule, func(string) string, error) {
+	for _, rule := range rules {
+		expand, ok := rule.match(input)
+		if !ok {
+			continue
+		}
+		if err := wait(ctx, s.fixture.Latency+rule.Latency); err != nil {
+			return FixtureRule{}, nil, err
+		}
+		if rule.Error != "" {
+			return FixtureRule{}, nil, fmt.Errorf("scripted %s error: %s", method, rule.Error)
+		}
+		return rule, expand, nil
+	}
+
+	if err := wait(ctx, s.fixture.Latency); err != nil {
+		return FixtureRule{}, nil, err
+	}
+	return FixtureRule{}, nil, ErrNoScriptedResponse{Method: method, Input: input}
+}
+
+// match reports whether the rule matches the input, and returns a function
+// expanding the pattern's groups in a template
+func (r FixtureRule) match(input string) (func(string) string, bool) {
+	if r.re == nil {
+		ok := strings.EqualFold(strings.TrimSpace(input), strings.TrimSpace(r.Input))
+		return func(template string) string { return template }, ok
+	}
+
+	submatches := r.re.FindStringSubmatchIndex(input)
+	if submatches == nil {
+		return nil, false
+	}
+	return func(template string) string {
+		return string(r.re.ExpandString(nil, template, input, submatches))
+	}, true
+}
+
+// wait sleeps for the given latency unless the context ends first
+func wait(ctx context.Context, latency time.Duration) error {
+	if latency <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
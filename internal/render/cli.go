@@ -3,48 +3,40 @@ package render
 import (
 	"bufio"
 	"fmt"
-	"os"
+	"io"
 	"strings"
 
 	"chatbot-go/internal/bot"
 )
 
-// SessionView defines the interface for accessing session data for rendering
-type SessionView interface {
-	GetVariables() map[string]string
-}
-
 // CLIRenderer renders conversation to CLI
 type CLIRenderer struct {
 	reader *bufio.Reader
+	out    io.Writer
 }
 
-// NewCLIRenderer creates a new CLI renderer
-func NewCLIRenderer() *CLIRenderer {
+// NewCLIRenderer creates a new CLI renderer reading replies from in and
+// writing the conversation to out
+func NewCLIRenderer(in io.Reader, out io.Writer) *CLIRenderer {
 	return &CLIRenderer{
-		reader: bufio.NewReader(os.Stdin),
+		reader: bufio.NewReader(in),
+		out:    out,
 	}
 }
 
-// RenderMessage renders a node message with variable interpolation
-func (r *CLIRenderer) RenderMessage(node *bot.Node, session SessionView) string {
-	message := node.Message
-	// Interpolate variables: {{var_name}}
-	for key, value := range session.GetVariables() {
-		placeholder := fmt.Sprintf("{{%s}}", key)
-		message = strings.ReplaceAll(message, placeholder, value)
-	}
-	return message
+// PrintMessage prints a message
+func (r *CLIRenderer) PrintMessage(message string) {
+	fmt.Fprintln(r.out, message)
 }
 
-// PrintMessage prints a message to stdout
-func (r *CLIRenderer) PrintMessage(message string) {
-	fmt.Println(message)
+// PrintError prints a recoverable error
+func (r *CLIRenderer) PrintError(err error) {
+	fmt.Fprintf(r.out, "Error: %v\n", err)
 }
 
-// ReadInput reads user input from stdin
+// ReadInput reads a line of user input
 func (r *CLIRenderer) ReadInput() (string, error) {
-	fmt.Print("> ")
+	fmt.Fprint(r.out, "> ")
 	input, err := r.reader.ReadString('\n')
 	if err != nil {
 		return "", err
@@ -57,13 +49,13 @@ func (r *CLIRenderer) ShowIntents(intents []bot.Intent) {
 	if len(intents) == 0 {
 		return
 	}
-	fmt.Println("\nAvailable options:")
+	fmt.Fprintln(r.out, "\nAvailable options:")
 	for i, intent := range intents {
 		if len(intent.Examples) > 0 {
-			fmt.Printf("  %d. %s (e.g., \"%s\")\n", i+1, intent.Name, intent.Examples[0])
+			fmt.Fprintf(r.out, "  %d. %s (e.g., \"%s\")\n", i+1, intent.Name, intent.Examples[0])
 		} else {
-			fmt.Printf("  %d. %s\n", i+1, intent.Name)
+			fmt.Fprintf(r.out, "  %d. %s\n", i+1, intent.Name)
 		}
 	}
-	fmt.Println()
+	fmt.Fprintln(r.out)
 }
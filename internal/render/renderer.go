@@ -0,0 +1,18 @@
+package render
+
+import "chatbot-go/internal/bot"
+
+// Renderer presents the conversation to the user and reads their replies
+type Renderer interface {
+	// PrintMessage shows a bot message to the user
+	PrintMessage(message string)
+
+	// ShowIntents shows the options available at the current node
+	ShowIntents(intents []bot.Intent)
+
+	// ReadInput reads the user's next reply
+	ReadInput() (string, error)
+
+	// PrintError reports a problem that did not end the conversation
+	PrintError(err error)
+}
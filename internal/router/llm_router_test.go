@@ -0,0 +1,53 @@
+package router
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"chatbot-go/internal/bot"
+	"chatbot-go/internal/llm"
+)
+
+func TestLLMRouterWithScriptedProvider(t *testing.T) {
+	provider, err := llm.NewScriptedProvider(llm.Fixture{
+		Classify: []llm.FixtureRule{
+			{Pattern: `(?i)money back`, Intent: "refund"},
+			{Input: "where is it", Intent: "track"},
+			{Input: "talk to someone", Intent: "handoff"},
+			{Input: "are you there", Error: "model offline"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewScriptedProvider: %v", err)
+	}
+	router := NewLLMRouter(provider)
+	intents := []bot.Intent{
+		{Name: "refund", Examples: []string{"refund"}, Next: "refund"},
+		{Name: "track", Examples: []string{"track my order"}, Next: "track"},
+	}
+
+	tests := []struct {
+		input string
+		want  string
+		err   string // substring of the expected error
+	}{
+		{input: "I want my Money Back", want: "refund"},
+		{input: "where is it", want: "track"},
+		{input: "talk to someone", err: "not one of the offered intents"},
+		{input: "are you there", err: "model offline"},
+		{input: "hello", err: "no scripted classify response"},
+	}
+	for _, tt := range tests {
+		got, err := router.Route(context.Background(), tt.input, intents)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Route(%q) = %q, %v; want an error containing %q", tt.input, got, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Route(%q) = %q, %v; want %q", tt.input, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package router
+
+import (
+	"chatbot-go/internal/bot"
+	"strings"
+)
+
+// Overlap is an intent example that the router sends to a different intent
+type Overlap struct {
+	Intent    string `json:"intent"` // intent the example belongs to
+	Example   string `json:"example"`
+	RoutedTo  string `json:"routed_to"`
+	Duplicate bool   `json:"duplicate"` // the other intent lists the same example
+
+	IntentIndex  int `json:"-"` // position of the intent in the list routed against
+	ExampleIndex int `json:"-"` // position of the example in its intent
+}
+
+// FindOverlaps runs every example of every intent through the router and
+// reports those routed to another intent. Each example is routed with itself
+// left out, so an exact match does not hide how the router treats input that
+// merely resembles it, such as "order" inside "track my order please".
+func FindOverlaps(r Router, intents []bot.Intent) []Overlap {
+	var overlaps []Overlap
+	for i, intent := range intents {
+		for j, example := range intent.Examples {
+			if other, ok := duplicateOf(intents, i, example); ok {
+				overlaps = append(overlaps, Overlap{
+					Intent:    intent.Name,
+					Example:   example,
+					RoutedTo:  other,
+					Duplicate: true,
+
+					IntentIndex:  i,
+					ExampleIndex: j,
+				})
+				continue
+			}
+
+			routed, err := r.Route(example, withoutExample(intents, i, j))
+			if err == nil && routed != intent.Name {
+				overlaps = append(overlaps, Overlap{
+					Intent:   intent.Name,
+					Example:  example,
+					RoutedTo: routed,
+
+					IntentIndex:  i,
+					ExampleIndex: j,
+				})
+			}
+		}
+	}
+	return overlaps
+}
+
+// duplicateOf returns another intent that lists the same example
+func duplicateOf(intents []bot.Intent, owner int, example string) (string, bool) {
+	for i, intent := range intents {
+		if i == owner {
+			continue
+		}
+		for _, other := range intent.Examples {
+			if strings.EqualFold(strings.TrimSpace(other), strings.TrimSpace(example)) {
+				return intent.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// withoutExample returns a copy of intents with one example removed
+func withoutExample(intents []bot.Intent, intentIndex, exampleIndex int) []bot.Intent {
+	out := make([]bot.Intent, len(intents))
+	copy(out, intents)
+
+	examples := intents[intentIndex].Examples
+	trimmed := make([]string, 0, len(examples)-1)
+	trimmed = append(trimmed, examples[:exampleIndex]...)
+	trimmed = append(trimmed, examples[exampleIndex+1:]...)
+	out[intentIndex].Examples = trimmed
+	return out
+}
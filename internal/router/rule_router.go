@@ -19,17 +19,13 @@ func (r *RuleRouter) Route(input string, intents []bot.Intent) (string, error) {
 		return "", ErrNoIntents{}
 	}
 
-	inputLower := strings.ToLower(strings.TrimSpace(input))
-
 	// 1. Exact match
-	for _, intent := range intents {
-		for _, example := range intent.Examples {
-			if strings.ToLower(example) == inputLower {
-				return intent.Name, nil
-			}
-		}
+	if intentName, ok := MatchExact(input, intents); ok {
+		return intentName, nil
 	}
 
+	inputLower := strings.ToLower(strings.TrimSpace(input))
+
 	// 2. Keyword/substring match
 	for _, intent := range intents {
 		for _, example := range intent.Examples {
@@ -70,6 +66,20 @@ func (r *RuleRouter) Route(input string, intents []bot.Intent) (string, error) {
 	return "", ErrNoMatch{}
 }
 
+// MatchExact returns the intent with an example equal to the input, ignoring
+// case and surrounding whitespace
+func MatchExact(input string, intents []bot.Intent) (string, bool) {
+	inputLower := strings.ToLower(strings.TrimSpace(input))
+	for _, intent := range intents {
+		for _, example := range intent.Examples {
+			if strings.ToLower(example) == inputLower {
+				return intent.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
 // ErrNoMatch indicates no intent matched
 type ErrNoMatch struct{}
 
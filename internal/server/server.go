@@ -0,0 +1,328 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"chatbot-go/internal/bot"
+	"chatbot-go/internal/engine"
+)
+
+// Server exposes a conversation engine as a JSON REST API:
+//
+//	POST   /sessions                 start a session
+//	GET    /sessions/{id}            fetch session state
+//	GET    /sessions/{id}/history    fetch conversation history
+//	POST   /sessions/{id}/messages   send a user message
+//	POST   /sessions/{id}/back       undo the previous turn
+//	DELETE /sessions/{id}            end a session
+//	GET    /ws                       converse over a WebSocket
+type Server struct {
+	engine         *engine.ConversationEngine
+	allowedOrigins []string
+}
+
+// New creates a server for the given conversation engine; WebSocket
+// connections are accepted from the same origin and from allowedOrigins
+// ("*" allows any origin)
+func New(ce *engine.ConversationEngine, allowedOrigins ...string) *Server {
+	return &Server{
+		engine:         ce,
+		allowedOrigins: allowedOrigins,
+	}
+}
+
+// ListenAndServe serves HTTP on addr until ctx is cancelled, expiring idle
+// sessions every expireEvery (disabled when zero)
+func (s *Server) ListenAndServe(ctx context.Context, addr string, expireEvery time.Duration) error {
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if expireEvery > 0 {
+		go s.expireLoop(ctx, expireEvery)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return httpServer.Shutdown(shutdownCtx)
+	}
+}
+
+// ServeHTTP routes API requests
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	if path == "ws" {
+		s.route(w, r, map[string]http.HandlerFunc{
+			http.MethodGet: s.handleWebSocket,
+		})
+		return
+	}
+
+	parts := strings.Split(path, "/")
+	if parts[0] != "sessions" || len(parts) > 3 {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	switch {
+	case len(parts) == 1:
+		s.route(w, r, map[string]http.HandlerFunc{
+			http.MethodPost: s.handleCreate,
+		})
+	case len(parts) == 2:
+		id := parts[1]
+		s.route(w, r, map[string]http.HandlerFunc{
+			http.MethodGet:    func(w http.ResponseWriter, r *http.Request) { s.handleState(w, r, id) },
+			http.MethodDelete: func(w http.ResponseWriter, r *http.Request) { s.handleDelete(w, r, id) },
+		})
+	case parts[2] == "messages":
+		id := parts[1]
+		s.route(w, r, map[string]http.HandlerFunc{
+			http.MethodPost: func(w http.ResponseWriter, r *http.Request) { s.handleMessage(w, r, id) },
+		})
+	case parts[2] == "history":
+		id := parts[1]
+		s.route(w, r, map[string]http.HandlerFunc{
+			http.MethodGet: func(w http.ResponseWriter, r *http.Request) { s.handleHistory(w, r, id) },
+		})
+	case parts[2] == "back":
+		id := parts[1]
+		s.route(w, r, map[string]http.HandlerFunc{
+			http.MethodPost: func(w http.ResponseWriter, r *http.Request) { s.handleBack(w, r, id) },
+		})
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// route dispatches on the request method
+func (s *Server) route(w http.ResponseWriter, r *http.Request, handlers map[string]http.HandlerFunc) {
+	handler, ok := handlers[r.Method]
+	if !ok {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed", r.Method))
+		return
+	}
+	handler(w, r)
+}
+
+// handleCreate starts a session and returns the bot's opening messages
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	id, err := s.engine.NewSession()
+	if err != nil {
+		writeEngineError(w, err)
+		return
+	}
+
+	resp, err := s.engine.Start(r.Context(), id)
+	if err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newResponseBody(resp))
+}
+
+// handleMessage feeds one user message to a session
+func (s *Server) handleMessage(w http.ResponseWriter, r *http.Request, id string) {
+	var req messageRequest
+	body := http.MaxBytesReader(w, r.Body, maxMessageSize)
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit))
+			return
+		}
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+
+	resp, err := s.engine.Step(r.Context(), id, req.Text)
+	if err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newResponseBody(resp))
+}
+
+// handleBack undoes the previous turn of a session
+func (s *Server) handleBack(w http.ResponseWriter, r *http.Request, id string) {
+	resp, err := s.engine.Back(r.Context(), id)
+	if err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newResponseBody(resp))
+}
+
+// handleState returns the session's current node and variables
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request, id string) {
+	session, err := s.engine.Session(id)
+	if err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stateBody{
+		SessionID:   session.ID,
+		Bot:         session.Bot,
+		CurrentNode: session.CurrentNode,
+		Variables:   session.Variables,
+		Turns:       len(session.History),
+	})
+}
+
+// handleHistory returns the session's recorded turns
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request, id string) {
+	session, err := s.engine.Session(id)
+	if err != nil {
+		writeEngineError(w, err)
+		return
+	}
+
+	history := make([]turnBody, len(session.History))
+	for i, turn := range session.History {
+		history[i] = turnBody{
+			Node:      turn.Node,
+			UserInput: turn.UserInput,
+			Response:  turn.Response,
+		}
+	}
+	writeJSON(w, http.StatusOK, historyBody{SessionID: session.ID, History: history})
+}
+
+// handleDelete ends a session
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request, id string) {
+	if err := s.engine.DeleteSession(id); err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// expireLoop periodically removes idle sessions
+func (s *Server) expireLoop(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.engine.ExpireSessions(); err != nil {
+				log.Printf("failed to expire sessions: %v", err)
+			}
+		}
+	}
+}
+
+// messageRequest is the body of a user message
+type messageRequest struct {
+	Text string `json:"text"`
+}
+
+// responseBody is the bot output for one step
+type responseBody struct {
+	SessionID string       `json:"session_id"`
+	Node      string       `json:"node"`
+	Messages  []string     `json:"messages"`
+	Intents   []intentBody `json:"intents"`
+	Terminal  bool         `json:"terminal"`
+}
+
+// intentBody describes an intent the user can choose next
+type intentBody struct {
+	Name     string   `json:"name"`
+	Examples []string `json:"examples,omitempty"`
+}
+
+// stateBody is the current state of a session
+type stateBody struct {
+	SessionID   string            `json:"session_id"`
+	Bot         string            `json:"bot"`
+	CurrentNode string            `json:"current_node"`
+	Variables   map[string]string `json:"variables"`
+	Turns       int               `json:"turns"`
+}
+
+// historyBody lists the turns of a session
+type historyBody struct {
+	SessionID string     `json:"session_id"`
+	History   []turnBody `json:"history"`
+}
+
+// turnBody is one recorded turn
+type turnBody struct {
+	Node      string `json:"node"`
+	UserInput string `json:"user_input"`
+	Response  string `json:"response"`
+}
+
+// errorBody is returned for failed requests
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+// newResponseBody converts an engine response for JSON output
+func newResponseBody(resp engine.Response) responseBody {
+	return responseBody{
+		SessionID: resp.SessionID,
+		Node:      resp.Node,
+		Messages:  resp.Messages,
+		Intents:   newIntentBodies(resp.Intents),
+		Terminal:  resp.Terminal,
+	}
+}
+
+// newIntentBodies lists intents without their target nodes
+func newIntentBodies(intents []bot.Intent) []intentBody {
+	bodies := make([]intentBody, len(intents))
+	for i, intent := range intents {
+		bodies[i] = intentBody{Name: intent.Name, Examples: intent.Examples}
+	}
+	return bodies
+}
+
+// writeEngineError maps engine errors to HTTP status codes
+func writeEngineError(w http.ResponseWriter, err error) {
+	var (
+		notFound engine.ErrSessionNotFound
+		ended    engine.ErrConversationEnded
+	)
+	switch {
+	case errors.As(err, &notFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.As(err, &ended):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// writeError writes a JSON error body
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorBody{Error: message})
+}
+
+// writeJSON writes a JSON response body
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"chatbot-go/internal/bot"
+	"chatbot-go/internal/engine"
+	"chatbot-go/internal/llm"
+)
+
+// testBot asks for a name, greets the user and ends
+const testBot = `
+bot:
+  name: greeter
+flows:
+  start:
+    message: "Name?"
+    input: {type: text, save_as: name}
+    next: greet
+  greet:
+    message: "Hi {{name}}"
+`
+
+// loadTestBot loads a bot definition written inline in a test
+func loadTestBot(t *testing.T, definition string) *bot.Bot {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bot.yaml")
+	if err := os.WriteFile(path, []byte(definition), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b, err := bot.LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("failed to load bot: %v", err)
+	}
+	return b
+}
+
+// newTestServer serves testBot over httptest
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(New(engine.NewConversationEngine(loadTestBot(t, testBot), llm.NewNoopProvider())))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// call sends a request with an optional body and decodes a JSON response
+// into out, returning the status code
+func call(t *testing.T, ts *httptest.Server, method, path, body string, out interface{}) int {
+	t.Helper()
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer res.Body.Close()
+	if out != nil && res.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: failed to decode response: %v", method, path, err)
+		}
+	}
+	return res.StatusCode
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	ts := newTestServer(t)
+
+	var created responseBody
+	if status := call(t, ts, http.MethodPost, "/sessions", "", &created); status != http.StatusCreated {
+		t.Fatalf("create: got status %d, want %d", status, http.StatusCreated)
+	}
+	if created.SessionID == "" || created.Node != "start" || len(created.Messages) != 1 || created.Messages[0] != "Name?" {
+		t.Fatalf("create: got %+v", created)
+	}
+	base := "/sessions/" + created.SessionID
+
+	var reply responseBody
+	if status := call(t, ts, http.MethodPost, base+"/messages", `{"text": "Ada"}`, &reply); status != http.StatusOK {
+		t.Fatalf("message: got status %d", status)
+	}
+	if reply.Node != "greet" || !reply.Terminal || len(reply.Messages) != 1 || reply.Messages[0] != "Hi Ada" {
+		t.Errorf("message: got %+v", reply)
+	}
+
+	var state stateBody
+	if status := call(t, ts, http.MethodGet, base, "", &state); status != http.StatusOK {
+		t.Fatalf("state: got status %d", status)
+	}
+	if state.CurrentNode != "greet" || state.Variables["name"] != "Ada" || state.Turns != 1 || state.Bot != "greeter" {
+		t.Errorf("state: got %+v", state)
+	}
+
+	var history historyBody
+	if status := call(t, ts, http.MethodGet, base+"/history", "", &history); status != http.StatusOK {
+		t.Fatalf("history: got status %d", status)
+	}
+	if len(history.History) != 1 || history.History[0].Node != "start" || history.History[0].UserInput != "Ada" {
+		t.Errorf("history: got %+v", history)
+	}
+
+	var back responseBody
+	if status := call(t, ts, http.MethodPost, base+"/back", "", &back); status != http.StatusOK {
+		t.Fatalf("back: got status %d", status)
+	}
+	if back.Node != "start" || back.Terminal {
+		t.Errorf("back: got %+v", back)
+	}
+	var restored stateBody
+	if call(t, ts, http.MethodGet, base, "", &restored); len(restored.Variables) != 0 || restored.Turns != 0 {
+		t.Errorf("state after back: got %+v", restored)
+	}
+
+	if status := call(t, ts, http.MethodDelete, base, "", nil); status != http.StatusNoContent {
+		t.Fatalf("delete: got status %d, want %d", status, http.StatusNoContent)
+	}
+	var errBody errorBody
+	if status := call(t, ts, http.MethodGet, base, "", &errBody); status != http.StatusNotFound {
+		t.Errorf("state after delete: got status %d, want %d", status, http.StatusNotFound)
+	}
+}
+
+func TestErrorStatuses(t *testing.T) {
+	ts := newTestServer(t)
+
+	var created responseBody
+	call(t, ts, http.MethodPost, "/sessions", "", &created)
+	base := "/sessions/" + created.SessionID
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"unknown route", http.MethodGet, "/nope", "", http.StatusNotFound},
+		{"unknown session action", http.MethodPost, base + "/nope", "", http.StatusNotFound},
+		{"unknown session", http.MethodGet, "/sessions/missing", "", http.StatusNotFound},
+		{"message to unknown session", http.MethodPost, "/sessions/missing/messages", `{"text": "hi"}`, http.StatusNotFound},
+		{"history of unknown session", http.MethodGet, "/sessions/missing/history", "", http.StatusNotFound},
+		{"back in unknown session", http.MethodPost, "/sessions/missing/back", "", http.StatusNotFound},
+		{"malformed body", http.MethodPost, base + "/messages", `{"text":`, http.StatusBadRequest},
+		{"wrong body type", http.MethodPost, base + "/messages", `["hi"]`, http.StatusBadRequest},
+		{"wrong method", http.MethodPut, base, "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body errorBody
+			if status := call(t, ts, tt.method, tt.path, tt.body, &body); status != tt.status {
+				t.Errorf("got status %d, want %d", status, tt.status)
+			}
+			if body.Error == "" {
+				t.Error("expected an error message")
+			}
+		})
+	}
+}
+
+func TestMessageAfterEndConflicts(t *testing.T) {
+	ts := newTestServer(t)
+
+	var created responseBody
+	call(t, ts, http.MethodPost, "/sessions", "", &created)
+	base := "/sessions/" + created.SessionID
+	call(t, ts, http.MethodPost, base+"/messages", `{"text": "Ada"}`, &responseBody{})
+
+	var body errorBody
+	if status := call(t, ts, http.MethodPost, base+"/messages", `{"text": "again"}`, &body); status != http.StatusConflict {
+		t.Errorf("got status %d, want %d", status, http.StatusConflict)
+	}
+}
+
+func TestMessageBodyLimit(t *testing.T) {
+	ts := newTestServer(t)
+
+	var created responseBody
+	call(t, ts, http.MethodPost, "/sessions", "", &created)
+	base := "/sessions/" + created.SessionID
+
+	large := `{"text": "` + strings.Repeat("a", maxMessageSize) + `"}`
+	var body errorBody
+	if status := call(t, ts, http.MethodPost, base+"/messages", large, &body); status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("got status %d, want %d", status, http.StatusRequestEntityTooLarge)
+	}
+
+	var state stateBody
+	call(t, ts, http.MethodGet, base, "", &state)
+	if state.CurrentNode != "start" || state.Turns != 0 {
+		t.Errorf("session changed by rejected message: %+v", state)
+	}
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"chatbot-go/internal/engine"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// writeWait is the time allowed to write a frame to the peer
+	writeWait = 10 * time.Second
+
+	// pongWait is the time allowed to read the next pong from the peer
+	pongWait = 60 * time.Second
+
+	// pingPeriod sends pings often enough to arrive within pongWait
+	pingPeriod = pongWait * 9 / 10
+
+	// maxMessageSize bounds a single client frame or REST message body
+	maxMessageSize = 64 * 1024
+)
+
+// wsClientMessage is a frame sent by the client: {"type": "message", "text": "..."}
+// sends user input and {"type": "back"} undoes the previous turn
+type wsClientMessage struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+}
+
+// wsEvent is a frame pushed to the client:
+//
+//	session  the session bound to the connection was created
+//	message  a complete bot message
+//	chunk    a fragment of LLM-generated text still being produced
+//	turn     the bot finished responding; carries node, intents and terminal
+//	error    the client frame could not be handled, or, with discard set,
+//	         generated text failed and its chunks should be dropped
+type wsEvent struct {
+	Type      string       `json:"type"`
+	SessionID string       `json:"session_id,omitempty"`
+	Text      string       `json:"text,omitempty"`
+	Node      string       `json:"node,omitempty"`
+	Intents   []intentBody `json:"intents,omitempty"`
+	Terminal  bool         `json:"terminal,omitempty"`
+	Error     string       `json:"error,omitempty"`
+	Discard   bool         `json:"discard,omitempty"`
+}
+
+// handleWebSocket binds a new connection to a new session and relays
+// messages in both directions until either side closes
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: s.checkOrigin,
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already written an HTTP error
+		return
+	}
+	defer conn.Close()
+
+	id, err := s.engine.NewSession()
+	if err != nil {
+		conn.WriteJSON(wsEvent{Type: "error", Error: err.Error()})
+		return
+	}
+	// The session lives only as long as the connection
+	defer s.engine.DeleteSession(id)
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	events := make(chan wsEvent, 16)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.writeLoop(conn, events, cancel)
+	}()
+	defer func() {
+		close(events)
+		<-done
+	}()
+
+	send := func(event wsEvent) {
+		select {
+		case events <- event:
+		case <-ctx.Done():
+		}
+	}
+	ctx = engine.WithStream(ctx, func(kind engine.StreamKind, text string) {
+		switch kind {
+		case engine.StreamChunk:
+			send(wsEvent{Type: "chunk", Text: text})
+		case engine.StreamAbort:
+			send(wsEvent{Type: "error", Error: text, Discard: true})
+		default:
+			send(wsEvent{Type: "message", Text: text})
+		}
+	})
+
+	send(wsEvent{Type: "session", SessionID: id})
+	resp, err := s.engine.Start(ctx, id)
+	if err != nil {
+		send(wsEvent{Type: "error", Error: err.Error()})
+		return
+	}
+	send(turnEvent(resp))
+
+	conn.SetReadLimit(maxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		// Any read error means the peer closed, went silent or broke the protocol
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var msg wsClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			send(wsEvent{Type: "error", Error: "invalid message: " + err.Error()})
+			continue
+		}
+
+		switch msg.Type {
+		case "message":
+			resp, err = s.engine.Step(ctx, id, msg.Text)
+		case "back":
+			resp, err = s.engine.Back(ctx, id)
+		default:
+			send(wsEvent{Type: "error", Error: "unknown message type: " + msg.Type})
+			continue
+		}
+		if err != nil {
+			send(wsEvent{Type: "error", Error: err.Error()})
+			continue
+		}
+		send(turnEvent(resp))
+	}
+}
+
+// writeLoop is the connection's only writer: it sends queued events and
+// keepalive pings, and tears the connection down if a write fails
+func (s *Server) writeLoop(conn *websocket.Conn, events <-chan wsEvent, cancel context.CancelFunc) {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+					time.Now().Add(writeWait))
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteJSON(event); err != nil {
+				cancel()
+				conn.Close()
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				cancel()
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// checkOrigin allows same-origin requests and any configured origins
+func (s *Server) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range s.allowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return origin == "http://"+r.Host || origin == "https://"+r.Host
+}
+
+// turnEvent marks the end of a bot response; messages were already streamed
+func turnEvent(resp engine.Response) wsEvent {
+	return wsEvent{
+		Type:      "turn",
+		SessionID: resp.SessionID,
+		Node:      resp.Node,
+		Intents:   newIntentBodies(resp.Intents),
+		Terminal:  resp.Terminal,
+	}
+}
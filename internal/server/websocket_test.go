@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"chatbot-go/internal/engine"
+	"chatbot-go/internal/llm"
+
+	"github.com/gorilla/websocket"
+)
+
+// generateBot greets the user with a message followed by generated text
+const generateBot = `
+bot:
+  name: streamer
+flows:
+  start:
+    message: "Hi"
+    generate: "Say hello"
+`
+
+// streamingProvider streams fixed chunks and then fails with err, if set
+type streamingProvider struct {
+	llm.NoopProvider
+	chunks []string
+	err    error
+}
+
+// StreamText sends the chunks in order
+func (p *streamingProvider) StreamText(ctx context.Context, prompt llm.Prompt, onChunk func(chunk string)) (string, error) {
+	for _, chunk := range p.chunks {
+		onChunk(chunk)
+	}
+	if p.err != nil {
+		return "", p.err
+	}
+	return strings.Join(p.chunks, ""), nil
+}
+
+// newWebSocketServer serves a conversation engine with the given origins allowed
+func newWebSocketServer(t *testing.T, ce *engine.ConversationEngine, allowedOrigins ...string) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(New(ce, allowedOrigins...))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// dial opens a WebSocket to the server, sending origin when it is set
+func dial(t *testing.T, ts *httptest.Server, origin string) (*websocket.Conn, *http.Response, error) {
+	t.Helper()
+	header := http.Header{}
+	if origin != "" {
+		header.Set("Origin", origin)
+	}
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws", header)
+	if conn != nil {
+		t.Cleanup(func() { conn.Close() })
+	}
+	return conn, resp, err
+}
+
+// readUntilTurn reads frames up to and including the next turn frame
+func readUntilTurn(t *testing.T, conn *websocket.Conn) []wsEvent {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var events []wsEvent
+	for {
+		var event wsEvent
+		if err := conn.ReadJSON(&event); err != nil {
+			t.Fatalf("read after %+v: %v", events, err)
+		}
+		events = append(events, event)
+		if event.Type == "turn" {
+			return events
+		}
+	}
+}
+
+func TestWebSocketStreamsGeneratedText(t *testing.T) {
+	provider := &streamingProvider{chunks: []string{"Hel", "lo"}}
+	ts := newWebSocketServer(t, engine.NewConversationEngine(loadTestBot(t, generateBot), provider))
+	conn, _, err := dial(t, ts, "")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+
+	events := readUntilTurn(t, conn)
+	want := []wsEvent{
+		{Type: "session"},
+		{Type: "message", Text: "Hi"},
+		{Type: "chunk", Text: "Hel"},
+		{Type: "chunk", Text: "lo"},
+		{Type: "message", Text: "Hello"},
+		{Type: "turn", Node: "start", Terminal: true},
+	}
+	assertEvents(t, events, want)
+}
+
+func TestWebSocketDiscardsFailedStream(t *testing.T) {
+	provider := &streamingProvider{chunks: []string{"Hel"}, err: errors.New("connection reset")}
+	ts := newWebSocketServer(t, engine.NewConversationEngine(loadTestBot(t, generateBot), provider))
+	conn, _, err := dial(t, ts, "")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+
+	events := readUntilTurn(t, conn)
+	want := []wsEvent{
+		{Type: "session"},
+		{Type: "message", Text: "Hi"},
+		{Type: "chunk", Text: "Hel"},
+		{Type: "error", Error: "text generation failed: connection reset", Discard: true},
+		{Type: "turn", Node: "start", Terminal: true},
+	}
+	assertEvents(t, events, want)
+}
+
+// assertEvents compares frames, ignoring session IDs
+func assertEvents(t *testing.T, got, want []wsEvent) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d frames %+v, want %d %+v", len(got), got, len(want), want)
+	}
+	for i := range want {
+		got[i].SessionID = ""
+		if got[i].Type != want[i].Type || got[i].Text != want[i].Text || got[i].Node != want[i].Node ||
+			got[i].Terminal != want[i].Terminal || got[i].Error != want[i].Error || got[i].Discard != want[i].Discard {
+			t.Errorf("frame %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWebSocketOriginCheck(t *testing.T) {
+	b := loadTestBot(t, testBot)
+
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string // "same" stands for the server's own origin
+		ok      bool
+	}{
+		{"no origin", nil, "", true},
+		{"same origin", nil, "same", true},
+		{"other origin", nil, "http://other.example", false},
+		{"allowed origin", []string{"http://app.example"}, "http://app.example", true},
+		{"origin not in the allowed list", []string{"http://app.example"}, "http://other.example", false},
+		{"any origin", []string{"*"}, "http://other.example", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newWebSocketServer(t, engine.NewConversationEngine(b, llm.NewNoopProvider()), tt.allowed...)
+			origin := tt.origin
+			if origin == "same" {
+				origin = ts.URL
+			}
+
+			_, resp, err := dial(t, ts, origin)
+			if tt.ok && err != nil {
+				t.Fatalf("dial: %v", err)
+			}
+			if !tt.ok {
+				if err == nil {
+					t.Fatal("dial: expected the origin to be rejected")
+				}
+				if resp == nil || resp.StatusCode != http.StatusForbidden {
+					t.Errorf("got response %v, want status %d", resp, http.StatusForbidden)
+				}
+			}
+		})
+	}
+}
+
+func TestWebSocketDeletesSessionOnDisconnect(t *testing.T) {
+	ce := engine.NewConversationEngine(loadTestBot(t, testBot), llm.NewNoopProvider())
+	ts := newWebSocketServer(t, ce)
+	conn, _, err := dial(t, ts, "")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+
+	events := readUntilTurn(t, conn)
+	id := events[0].SessionID
+	if _, err := ce.Session(id); err != nil {
+		t.Fatalf("session while connected: %v", err)
+	}
+
+	conn.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, err := ce.Session(id)
+		var notFound engine.ErrSessionNotFound
+		if errors.As(err, &notFound) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("session %s still exists after the connection closed (err %v)", id, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
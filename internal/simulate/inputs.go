@@ -0,0 +1,162 @@
+package simulate
+
+import (
+	"math/rand"
+	"regexp/syntax"
+	"strings"
+
+	"chatbot-go/internal/bot"
+)
+
+// gibberish is input no intent or typed input should accept
+var gibberish = []string{"qwzx", "???", "purple elephant", "asdf jkl", "0xdeadbeef"}
+
+// validInputs are accepted values for typed inputs that take free-form values
+var validInputs = map[string][]string{
+	"text":    {"hello", "Ada Lovelace", "12345", "something else"},
+	"number":  {"0", "42", "3.14", "-7"},
+	"integer": {"0", "1", "42", "-3"},
+	"email":   {"ada@example.com", "test.user@example.org"},
+	"phone":   {"+1 555 123 4567", "555-0100", "(020) 7946 0000"},
+	"date":    {"2024-03-15", "12/31/2025", "1 Jan 2026"},
+	"time":    {"10:30am", "15:04", "9pm"},
+	"yes_no":  {"yes", "no", "y", "nope"},
+}
+
+// invalidInputs are values typed inputs reject
+var invalidInputs = map[string][]string{
+	"number":  {"many", "1,2,3"},
+	"integer": {"1.5", "lots"},
+	"email":   {"not an email", "ada@"},
+	"phone":   {"call me", "12"},
+	"date":    {"someday", "2024-13-45"},
+	"time":    {"noonish", "25:99"},
+	"yes_no":  {"maybe", "perhaps"},
+	"choice":  {"none of those", "???"},
+	"regex":   {"!!!", ""},
+}
+
+// pick returns a random element of a non-empty list
+func pick(rng *rand.Rand, list []string) string {
+	return list[rng.Intn(len(list))]
+}
+
+// intentInput returns one of the examples of a random intent
+func intentInput(rng *rand.Rand, intents []bot.Intent) (string, bool) {
+	var withExamples []bot.Intent
+	for _, intent := range intents {
+		if len(intent.Examples) > 0 {
+			withExamples = append(withExamples, intent)
+		}
+	}
+	if len(withExamples) == 0 {
+		return "", false
+	}
+	intent := withExamples[rng.Intn(len(withExamples))]
+	return pick(rng, intent.Examples), true
+}
+
+// validInput returns a value the typed input accepts
+func validInput(rng *rand.Rand, in *bot.Input) string {
+	switch in.Type {
+	case "choice":
+		if len(in.Options) > 0 {
+			return pick(rng, in.Options)
+		}
+	case "regex":
+		if value, ok := matchingString(rng, in.Pattern); ok {
+			return value
+		}
+	case "":
+		return pick(rng, validInputs["text"])
+	}
+	if values, ok := validInputs[in.Type]; ok {
+		return pick(rng, values)
+	}
+	return pick(rng, gibberish)
+}
+
+// invalidInput returns a value the typed input rejects, or any value for
+// inputs that accept everything
+func invalidInput(rng *rand.Rand, in *bot.Input) string {
+	if values, ok := invalidInputs[in.Type]; ok {
+		return pick(rng, values)
+	}
+	return pick(rng, gibberish)
+}
+
+// matchingString generates a string matching a regular expression
+func matchingString(rng *rand.Rand, pattern string) (string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	if !generate(rng, re.Simplify(), &b) {
+		return "", false
+	}
+	return b.String(), true
+}
+
+// generate writes a random string matching a parsed expression, reporting
+// false for constructs it cannot generate
+func generate(rng *rand.Rand, re *syntax.Regexp, b *strings.Builder) bool {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine,
+		syntax.OpBeginText, syntax.OpEndText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return true
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+		return true
+	case syntax.OpCharClass:
+		// Rune holds inclusive ranges as pairs
+		if len(re.Rune) == 0 {
+			return false
+		}
+		i := rng.Intn(len(re.Rune)/2) * 2
+		lo, hi := re.Rune[i], re.Rune[i+1]
+		if lo < ' ' && hi >= ' ' {
+			lo = ' ' // skip control characters in negated classes
+		}
+		if hi-lo > 94 {
+			hi = lo + 94 // stay within printable ranges of broad classes
+		}
+		b.WriteRune(lo + rune(rng.Intn(int(hi-lo)+1)))
+		return true
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteRune(rune('a' + rng.Intn(26)))
+		return true
+	case syntax.OpCapture:
+		return generate(rng, re.Sub[0], b)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !generate(rng, sub, b) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpAlternate:
+		return generate(rng, re.Sub[rng.Intn(len(re.Sub))], b)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		lo, hi := 0, 3
+		switch re.Op {
+		case syntax.OpPlus:
+			lo = 1
+		case syntax.OpQuest:
+			hi = 1
+		case syntax.OpRepeat:
+			lo, hi = re.Min, re.Max
+			if hi < 0 {
+				hi = lo + 3
+			}
+		}
+		for n := lo + rng.Intn(hi-lo+1); n > 0; n-- {
+			if !generate(rng, re.Sub[0], b) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,126 @@
+package simulate
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Severity ranks a finding
+type Severity string
+
+const (
+	// SeverityError marks a turn that failed or rendered broken output
+	SeverityError Severity = "error"
+
+	// SeverityWarning marks something random walks may simply have missed
+	SeverityWarning Severity = "warning"
+)
+
+// Kind classifies a finding
+type Kind string
+
+const (
+	// KindCrash is a turn that panicked
+	KindCrash Kind = "crash"
+
+	// KindError is a turn that returned an error
+	KindError Kind = "error"
+
+	// KindLoop is a turn that followed sub-flow calls and returns forever
+	KindLoop Kind = "loop"
+
+	// KindNoEnd is a session that did not end within the turn limit
+	KindNoEnd Kind = "no-end"
+
+	// KindPlaceholder is a message with template syntax left in it
+	KindPlaceholder Kind = "placeholder"
+
+	// KindUnreached is a terminal node no session reached
+	KindUnreached Kind = "unreached"
+)
+
+// Finding is a problem found while walking sessions. Repeats of the same
+// problem are counted on the first session that found it.
+type Finding struct {
+	Severity Severity `json:"severity"`
+	Kind     Kind     `json:"kind"`
+	Session  int      `json:"session,omitempty"` // 1-based
+	Seed     int64    `json:"seed"`              // replays this session alone with --sessions 1
+	Node     string   `json:"node,omitempty"`
+	Message  string   `json:"message"`
+	Inputs   []string `json:"inputs,omitempty"` // user inputs up to the problem
+	Count    int      `json:"count"`
+}
+
+// Report is the outcome of a simulation
+type Report struct {
+	Seed     int64     `json:"seed"`
+	Sessions int       `json:"sessions"`
+	Turns    int       `json:"turns"`
+	Ended    int       `json:"ended"` // sessions that reached a terminal node
+	Findings []Finding `json:"findings"`
+
+	index map[string]int
+}
+
+// newReport creates an empty report for a simulation
+func newReport(options Options) *Report {
+	return &Report{
+		Seed:     options.Seed,
+		Findings: []Finding{},
+		index:    make(map[string]int),
+	}
+}
+
+// add records a finding, or counts it again if it was already found
+func (r *Report) add(f Finding) {
+	key := string(f.Kind) + "\x00" + f.Node + "\x00" + f.Message
+	if i, seen := r.index[key]; seen {
+		r.Findings[i].Count++
+		return
+	}
+	f.Count = 1
+	r.index[key] = len(r.Findings)
+	r.Findings = append(r.Findings, f)
+}
+
+// HasErrors reports whether any finding is an error
+func (r *Report) HasErrors() bool {
+	for _, f := range r.Findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteText prints a summary followed by each finding and the inputs that
+// led to it
+func (r *Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Simulated %d session(s), %d turn(s) with seed %d; %d session(s) ended\n", r.Sessions, r.Turns, r.Seed, r.Ended)
+	for _, f := range r.Findings {
+		location := ""
+		if f.Node != "" {
+			location = fmt.Sprintf(" at node '%s'", f.Node)
+		}
+		times := ""
+		if f.Count > 1 {
+			times = fmt.Sprintf(" (%d times)", f.Count)
+		}
+		fmt.Fprintf(w, "%s: %s%s: %s%s\n", f.Severity, f.Kind, location, f.Message, times)
+
+		if f.Kind == KindUnreached {
+			continue
+		}
+		quoted := make([]string, len(f.Inputs))
+		for i, input := range f.Inputs {
+			quoted[i] = fmt.Sprintf("%q", input)
+		}
+		fmt.Fprintf(w, "    session %d, reproduce with --seed %d --sessions 1\n", f.Session, f.Seed)
+		if len(quoted) > 0 {
+			fmt.Fprintf(w, "    inputs: %s\n", strings.Join(quoted, ", "))
+		}
+	}
+	fmt.Fprintf(w, "%d finding(s)\n", len(r.Findings))
+}
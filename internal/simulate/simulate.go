@@ -0,0 +1,189 @@
+package simulate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+
+	"chatbot-go/internal/bot"
+	"chatbot-go/internal/engine"
+	"chatbot-go/internal/graph"
+	"chatbot-go/internal/llm"
+)
+
+// placeholder matches template syntax left in a rendered message
+var placeholder = regexp.MustCompile(`\{\{[^}]*\}\}|<no value>`)
+
+// Options configures a simulation
+type Options struct {
+	Sessions int   // sessions to walk
+	MaxTurns int   // turns per session before it counts as not ending
+	Seed     int64 // session i walks with seed Seed+i
+}
+
+// Simulator walks random conversations through a bot
+type Simulator struct {
+	bot      *bot.Bot
+	provider llm.Provider
+	options  Options
+	opts     []engine.Option
+}
+
+// New creates a simulator; conversations run on one engine built with the
+// given provider and engine options
+func New(b *bot.Bot, provider llm.Provider, options Options, opts ...engine.Option) *Simulator {
+	return &Simulator{
+		bot:      b,
+		provider: provider,
+		options:  options,
+		opts:     opts,
+	}
+}
+
+// Run walks every session and reports what went wrong
+func (s *Simulator) Run(ctx context.Context) *Report {
+	ce := engine.NewConversationEngine(s.bot, s.provider, s.opts...)
+	report := newReport(s.options)
+	reached := make(map[string]bool)
+	for i := 0; i < s.options.Sessions; i++ {
+		s.walk(ctx, ce, i, report, reached)
+	}
+
+	for _, node := range graph.Build(s.bot).Nodes {
+		if node.Kind == graph.KindTerminal && !reached[node.Name] {
+			report.add(Finding{
+				Severity: SeverityWarning,
+				Kind:     KindUnreached,
+				Node:     node.Name,
+				Message:  "terminal node was never reached by any session",
+			})
+		}
+	}
+	return report
+}
+
+// walk plays one random session, recording the terminal node it reaches
+func (s *Simulator) walk(ctx context.Context, ce *engine.ConversationEngine, index int, report *Report, reached map[string]bool) {
+	seed := s.options.Seed + int64(index)
+	rng := rand.New(rand.NewSource(seed))
+	id, err := ce.NewSession()
+	if err != nil {
+		report.add(Finding{Severity: SeverityError, Kind: KindError, Session: index + 1, Seed: seed, Message: err.Error()})
+		return
+	}
+	defer ce.DeleteSession(id)
+
+	var inputs []string
+	found := func(kind Kind, node string, err error) {
+		severity := SeverityError
+		if kind == KindNoEnd {
+			severity = SeverityWarning
+		}
+		report.add(Finding{
+			Severity: severity,
+			Kind:     kind,
+			Session:  index + 1,
+			Seed:     seed,
+			Node:     node,
+			Message:  err.Error(),
+			Inputs:   append([]string(nil), inputs...),
+		})
+	}
+
+	report.Sessions++
+	resp, err := protect(func() (engine.Response, error) { return ce.Start(ctx, id) })
+	node := "start"
+	for turn := 0; ; turn++ {
+		if err != nil {
+			found(classify(err), node, err)
+			return
+		}
+		node = resp.Node
+		for _, message := range resp.Messages {
+			if match := placeholder.FindString(message); match != "" {
+				found(KindPlaceholder, node, fmt.Errorf("message contains unrendered %s: %q", match, message))
+			}
+		}
+		if resp.Terminal {
+			reached[node] = true
+			report.Ended++
+			return
+		}
+		if turn == s.options.MaxTurns {
+			found(KindNoEnd, "", fmt.Errorf("conversation did not end within %d turns", s.options.MaxTurns))
+			return
+		}
+
+		input := s.input(rng, s.bot.Flows[node])
+		inputs = append(inputs, input)
+		report.Turns++
+		resp, err = protect(func() (engine.Response, error) { return ce.Step(ctx, id, input) })
+	}
+}
+
+// input picks the next user input for a node: mostly something the node
+// expects, sometimes a global intent and sometimes something it rejects
+func (s *Simulator) input(rng *rand.Rand, node *bot.Node) string {
+	roll := rng.Float64()
+	if roll < 0.1 && s.bot.GlobalIntents != nil {
+		intents := make([]bot.Intent, len(s.bot.GlobalIntents.Intents))
+		for i, global := range s.bot.GlobalIntents.Intents {
+			intents[i] = global.Intent
+		}
+		if input, ok := intentInput(rng, intents); ok {
+			return input
+		}
+	}
+
+	switch {
+	case node == nil:
+	case node.Input != nil:
+		if roll > 0.85 {
+			return invalidInput(rng, node.Input)
+		}
+		return validInput(rng, node.Input)
+	case len(node.Intents) > 0:
+		if roll > 0.9 {
+			break
+		}
+		if input, ok := intentInput(rng, node.Intents); ok {
+			return input
+		}
+	}
+	return pick(rng, gibberish)
+}
+
+// protect runs a turn, turning a panic into an ErrPanic
+func protect(turn func() (engine.Response, error)) (resp engine.Response, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = ErrPanic{Value: r}
+		}
+	}()
+	return turn()
+}
+
+// classify names the kind of finding a failed turn is
+func classify(err error) Kind {
+	var panicked ErrPanic
+	var limit engine.ErrTransitionLimit
+	switch {
+	case errors.As(err, &panicked):
+		return KindCrash
+	case errors.As(err, &limit):
+		return KindLoop
+	default:
+		return KindError
+	}
+}
+
+// ErrPanic is a panic recovered from a turn
+type ErrPanic struct {
+	Value any
+}
+
+func (e ErrPanic) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
@@ -0,0 +1,114 @@
+package tmpl
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+// valueLayouts are the formats stored variables are parsed with by date;
+// captured dates and times are normalized to the first two
+var valueLayouts = []string{
+	"2006-01-02",
+	"15:04",
+	"2006-01-02 15:04",
+	time.RFC3339,
+}
+
+// Funcs are the helpers available in message templates
+var Funcs = template.FuncMap{
+	"default": defaultValue,
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"title":   title,
+	"trim":    strings.TrimSpace,
+	"join":    join,
+	"split":   split,
+	"date":    formatDate,
+	"now":     time.Now,
+}
+
+// defaultValue returns value, or fallback when value is missing or empty:
+// {{.milk | default "whole milk"}}
+func defaultValue(fallback string, value any) string {
+	if value == nil {
+		return fallback
+	}
+	s := fmt.Sprint(value)
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+// title capitalizes the first letter of each word
+func title(s string) string {
+	var out strings.Builder
+	start := true
+	for _, r := range s {
+		if start {
+			out.WriteRune(unicode.ToUpper(r))
+		} else {
+			out.WriteRune(r)
+		}
+		start = unicode.IsSpace(r)
+	}
+	return out.String()
+}
+
+// join joins the non-empty values with sep; lists from split are flattened:
+// {{join ", " .size .drink}}
+func join(sep string, values ...any) string {
+	var parts []string
+	for _, value := range values {
+		switch v := value.(type) {
+		case []string:
+			for _, s := range v {
+				if s != "" {
+					parts = append(parts, s)
+				}
+			}
+		case nil:
+		default:
+			if s := fmt.Sprint(v); s != "" {
+				parts = append(parts, s)
+			}
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+// split splits s around sep and trims each part
+func split(sep, s string) []string {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, sep)
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return parts
+}
+
+// formatDate formats a time, or a stored date or time variable, with a Go
+// time layout: {{.pickup_date | date "Mon, Jan 2"}}. Values that are not
+// dates are returned unchanged.
+func formatDate(layout string, value any) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(layout)
+	case string:
+		for _, valueLayout := range valueLayouts {
+			if t, err := time.Parse(valueLayout, v); err == nil {
+				return t.Format(layout)
+			}
+		}
+		return v
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,72 @@
+package tmpl
+
+import "fmt"
+
+// MissingMode selects how a template renders a variable that is not set
+type MissingMode string
+
+const (
+	// MissingBlank renders unset variables as empty text
+	MissingBlank MissingMode = "blank"
+
+	// MissingDefault renders unset variables as the policy's default text
+	MissingDefault MissingMode = "default"
+
+	// MissingError fails rendering when a variable is not set
+	MissingError MissingMode = "error"
+)
+
+// Missing is the policy for variables a template prints but the session has
+// not set. References guarded by if, with or the default helper are exempt.
+type Missing struct {
+	Mode    MissingMode
+	Default string
+}
+
+// NewMissing creates a missing-variable policy, checking the mode is known
+func NewMissing(mode, def string) (Missing, error) {
+	switch MissingMode(mode) {
+	case MissingBlank, MissingDefault, MissingError:
+		return Missing{Mode: MissingMode(mode), Default: def}, nil
+	case "":
+		return Missing{Mode: MissingBlank, Default: def}, nil
+	default:
+		return Missing{}, fmt.Errorf("unknown missing-variable mode '%s': use blank, default or error", mode)
+	}
+}
+
+// apply returns the variables to render with, filling in or rejecting the
+// required variables that are not set
+func (m Missing) apply(required []string, vars map[string]string) (map[string]string, error) {
+	var filled map[string]string
+	for _, name := range required {
+		if _, ok := vars[name]; ok {
+			continue
+		}
+
+		switch m.Mode {
+		case MissingError:
+			return nil, ErrMissingVariable(name)
+		case MissingDefault:
+			if filled == nil {
+				filled = make(map[string]string, len(vars)+1)
+				for k, v := range vars {
+					filled[k] = v
+				}
+			}
+			filled[name] = m.Default
+		}
+	}
+
+	if filled != nil {
+		return filled, nil
+	}
+	return vars, nil
+}
+
+// ErrMissingVariable indicates a template printed a variable that is not set
+type ErrMissingVariable string
+
+func (e ErrMissingVariable) Error() string {
+	return fmt.Sprintf("variable '%s' is not set", string(e))
+}
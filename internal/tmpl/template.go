@@ -0,0 +1,104 @@
+package tmpl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// legacyPlaceholder matches the original {{var_name}} interpolation syntax
+var legacyPlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_-]*)\s*\}\}`)
+
+// reserved are names that keep their template meaning inside {{ }} instead of
+// being read as a legacy variable placeholder
+var reserved = map[string]bool{
+	"if": true, "else": true, "end": true, "range": true, "with": true,
+	"define": true, "template": true, "block": true, "break": true,
+	"continue": true, "nil": true, "true": true, "false": true,
+	"and": true, "or": true, "not": true, "len": true, "index": true,
+	"slice": true, "print": true, "printf": true, "println": true,
+	"html": true, "js": true, "urlquery": true, "call": true,
+	"eq": true, "ne": true, "lt": true, "le": true, "gt": true, "ge": true,
+}
+
+// Template is a compiled message template rendered against session variables
+type Template struct {
+	text      string
+	tmpl      *template.Template
+	variables []string
+}
+
+// Parse compiles text as a template. Besides the full text/template language
+// with the helpers in Funcs, the original {{var_name}} placeholders keep working.
+func Parse(name, text string) (*Template, error) {
+	t := &Template{text: text}
+	if !strings.Contains(text, "{{") {
+		return t, nil
+	}
+
+	parsed, err := template.New(name).
+		Funcs(Funcs).
+		Option("missingkey=zero").
+		Parse(upgradeLegacy(text))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	t.tmpl = parsed
+	t.variables = requiredVariables(parsed)
+	return t, nil
+}
+
+// Execute renders the template with the given variables, handling unset
+// ones according to the missing-variable policy
+func (t *Template) Execute(vars map[string]string, missing Missing) (string, error) {
+	if t.tmpl == nil {
+		return t.text, nil
+	}
+
+	vars, err := missing.apply(t.variables, vars)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	if err := t.tmpl.Execute(&out, vars); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return out.String(), nil
+}
+
+// Render compiles and renders text in one step
+func Render(text string, vars map[string]string, missing Missing) (string, error) {
+	t, err := Parse("text", text)
+	if err != nil {
+		return "", err
+	}
+	return t.Execute(vars, missing)
+}
+
+// upgradeLegacy rewrites {{var_name}} as a lookup of the variable
+func upgradeLegacy(text string) string {
+	return legacyPlaceholder.ReplaceAllStringFunc(text, func(match string) string {
+		name := legacyPlaceholder.FindStringSubmatch(match)[1]
+		if reserved[name] || Funcs[name] != nil {
+			return match
+		}
+		if strings.Contains(name, "-") {
+			return fmt.Sprintf("{{index . %q}}", name)
+		}
+		return "{{." + name + "}}"
+	})
+}
+
+// HelperPlaceholders returns the helpers written in the short {{name}} form,
+// which call the helper rather than print a variable of the same name
+func HelperPlaceholders(text string) []string {
+	var names []string
+	for _, match := range legacyPlaceholder.FindAllStringSubmatch(text, -1) {
+		if name := match[1]; !reserved[name] && Funcs[name] != nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
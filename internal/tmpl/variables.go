@@ -0,0 +1,142 @@
+package tmpl
+
+import (
+	"sort"
+	"text/template"
+	"text/template/parse"
+)
+
+// Variables returns the session variables the template prints without a
+// guard. A reference is guarded inside {{if .x}} or {{with .x}} and when it
+// is piped through default, since those already handle an unset variable.
+func (t *Template) Variables() []string {
+	return t.variables
+}
+
+// requiredVariables finds the unguarded variable references in a template
+func requiredVariables(t *template.Template) []string {
+	found := make(map[string]bool)
+	for _, tree := range t.Templates() {
+		if tree.Tree != nil {
+			walkNode(tree.Tree.Root, nil, true, found)
+		}
+	}
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// walkNode records unguarded variable references below node. dotIsVars is
+// false inside with and range bodies, where dot no longer holds the variables.
+func walkNode(node parse.Node, guarded map[string]bool, dotIsVars bool, found map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkNode(child, guarded, dotIsVars, found)
+		}
+	case *parse.ActionNode:
+		walkPipe(n.Pipe, guarded, dotIsVars, found)
+	case *parse.TemplateNode:
+		walkPipe(n.Pipe, guarded, dotIsVars, found)
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, guarded, dotIsVars, dotIsVars, found)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, guarded, dotIsVars, false, found)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, guarded, dotIsVars, false, found)
+	}
+}
+
+// walkBranch treats the variables tested by an if, with or range as guarded
+// in its body
+func walkBranch(n *parse.BranchNode, guarded map[string]bool, dotIsVars, bodyDotIsVars bool, found map[string]bool) {
+	tested := make(map[string]bool)
+	collectPipe(n.Pipe, dotIsVars, tested)
+
+	inner := make(map[string]bool, len(guarded)+len(tested))
+	for name := range guarded {
+		inner[name] = true
+	}
+	for name := range tested {
+		inner[name] = true
+	}
+
+	walkNode(n.List, inner, bodyDotIsVars, found)
+	walkNode(n.ElseList, guarded, dotIsVars, found)
+}
+
+// walkPipe records the variables a printed pipeline uses, unless it falls
+// back to a default
+func walkPipe(pipe *parse.PipeNode, guarded map[string]bool, dotIsVars bool, found map[string]bool) {
+	if pipe == nil || usesDefault(pipe) {
+		return
+	}
+
+	used := make(map[string]bool)
+	collectPipe(pipe, dotIsVars, used)
+	for name := range used {
+		if !guarded[name] {
+			found[name] = true
+		}
+	}
+}
+
+// usesDefault reports whether any command in the pipeline calls default
+func usesDefault(pipe *parse.PipeNode) bool {
+	for _, cmd := range pipe.Cmds {
+		if len(cmd.Args) > 0 {
+			if ident, ok := cmd.Args[0].(*parse.IdentifierNode); ok && ident.Ident == "default" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// collectPipe adds every variable referenced in a pipeline to names
+func collectPipe(pipe *parse.PipeNode, dotIsVars bool, names map[string]bool) {
+	if pipe == nil {
+		return
+	}
+	for _, cmd := range pipe.Cmds {
+		// {{index . "name"}} is how hyphenated names are looked up
+		if len(cmd.Args) == 3 && dotIsVars {
+			ident, isIdent := cmd.Args[0].(*parse.IdentifierNode)
+			_, isDot := cmd.Args[1].(*parse.DotNode)
+			key, isString := cmd.Args[2].(*parse.StringNode)
+			if isIdent && ident.Ident == "index" && isDot && isString {
+				names[key.Text] = true
+				continue
+			}
+		}
+		for _, arg := range cmd.Args {
+			collectArg(arg, dotIsVars, names)
+		}
+	}
+}
+
+// collectArg adds the variable a command argument refers to
+func collectArg(arg parse.Node, dotIsVars bool, names map[string]bool) {
+	switch a := arg.(type) {
+	case *parse.FieldNode:
+		if dotIsVars {
+			names[a.Ident[0]] = true
+		}
+	case *parse.VariableNode:
+		// $.name always refers to the variables
+		if a.Ident[0] == "$" && len(a.Ident) > 1 {
+			names[a.Ident[1]] = true
+		}
+	case *parse.PipeNode:
+		collectPipe(a, dotIsVars, names)
+	case *parse.ChainNode:
+		collectArg(a.Node, dotIsVars, names)
+	}
+}
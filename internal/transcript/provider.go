@@ -0,0 +1,86 @@
+package transcript
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"chatbot-go/internal/engine"
+	"chatbot-go/internal/llm"
+)
+
+// ErrNoRecordedCall is returned by a replay provider asked for a call the
+// transcript does not have
+type ErrNoRecordedCall string
+
+func (e ErrNoRecordedCall) Error() string {
+	return fmt.Sprintf("no recorded LLM response for %s call", string(e))
+}
+
+// replayProvider answers LLM calls with the responses recorded for the
+// turn being replayed, noting requests that differ from the recording
+type replayProvider struct {
+	calls      []engine.LLMCall
+	used       int
+	mismatches []string
+}
+
+// load queues the recorded LLM calls of the next turn
+func (p *replayProvider) load(calls []engine.LLMCall) {
+	p.calls = calls
+	p.used = 0
+	p.mismatches = nil
+}
+
+// unused describes recorded calls the replayed turn did not make
+func (p *replayProvider) unused() []string {
+	var notes []string
+	for _, call := range p.calls[p.used:] {
+		notes = append(notes, fmt.Sprintf("recorded LLM %s call for %q was not made", call.Method, call.Request))
+	}
+	return notes
+}
+
+// next returns the next recorded call, which must be of the given method
+func (p *replayProvider) next(method, request string) (engine.LLMCall, error) {
+	if p.used >= len(p.calls) || p.calls[p.used].Method != method {
+		p.mismatches = append(p.mismatches, fmt.Sprintf("LLM %s call for %q was not recorded", method, request))
+		return engine.LLMCall{}, ErrNoRecordedCall(method)
+	}
+
+	call := p.calls[p.used]
+	p.used++
+	if call.Request != request {
+		p.mismatches = append(p.mismatches, fmt.Sprintf("LLM %s request differs: recorded %q, replayed %q", method, call.Request, request))
+	}
+	if call.Error != "" {
+		return call, errors.New(call.Error)
+	}
+	return call, nil
+}
+
+// ClassifyIntent returns the recorded intent
+func (p *replayProvider) ClassifyIntent(ctx context.Context, input string, intents []llm.Intent) (string, error) {
+	call, err := p.next("classify", input)
+	return call.Response, err
+}
+
+// ExtractEntities returns the recorded entities
+func (p *replayProvider) ExtractEntities(ctx context.Context, input string, schema map[string]string) (map[string]string, error) {
+	call, err := p.next("extract", input)
+	if err != nil || call.Response == "" {
+		return nil, err
+	}
+	var entities map[string]string
+	if err := json.Unmarshal([]byte(call.Response), &entities); err != nil {
+		return nil, fmt.Errorf("invalid recorded entities: %w", err)
+	}
+	return entities, nil
+}
+
+// GenerateText returns the recorded text
+func (p *replayProvider) GenerateText(ctx context.Context, prompt llm.Prompt) (string, error) {
+	call, err := p.next("generate", prompt.Text)
+	return call.Response, err
+}
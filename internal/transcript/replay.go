@@ -0,0 +1,215 @@
+package transcript
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"chatbot-go/internal/bot"
+	"chatbot-go/internal/engine"
+	"chatbot-go/internal/textdiff"
+)
+
+// Result is the outcome of replaying one recorded session
+type Result struct {
+	Session    string      `json:"session"`
+	Turns      int         `json:"turns"` // turns replayed, up to and including any divergence
+	Divergence *Divergence `json:"divergence,omitempty"`
+}
+
+// Divergence is the first turn whose replay behaved differently from the
+// recording
+type Divergence struct {
+	Turn        int      `json:"turn"` // 1-based position in the session
+	Action      string   `json:"action"`
+	Input       string   `json:"input,omitempty"`
+	Differences []string `json:"differences"`
+}
+
+// collector keeps the records of replayed turns
+type collector struct {
+	turns []engine.TurnRecord
+}
+
+// Record keeps a turn record (implements engine.Recorder)
+func (c *collector) Record(turn engine.TurnRecord) error {
+	c.turns = append(c.turns, turn)
+	return nil
+}
+
+// Replay re-runs each recorded session against the bot in a session resumed
+// from the state its first recorded turn started in, answering LLM calls
+// with the recorded responses, and stops each session at its first turn that
+// diverges from the recording
+func Replay(ctx context.Context, b *bot.Bot, turns []engine.TurnRecord, opts ...engine.Option) ([]Result, error) {
+	sessions := Sessions(turns)
+	results := make([]Result, 0, len(sessions))
+	for _, recorded := range sessions {
+		if recorded[0].Bot != "" && recorded[0].Bot != b.Name {
+			return nil, fmt.Errorf("session %s was recorded with bot '%s', not '%s'", recorded[0].Session, recorded[0].Bot, b.Name)
+		}
+		result, err := replaySession(ctx, b, recorded, opts)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// replaySession replays the turns of one recorded session
+func replaySession(ctx context.Context, b *bot.Bot, recorded []engine.TurnRecord, opts []engine.Option) (Result, error) {
+	first := recorded[0]
+	result := Result{Session: first.Session}
+
+	// Sessions resumed with --session do not begin at start, so the replay
+	// picks up from the session state recorded before the first turn
+	store := engine.NewMemoryStore()
+	initial := engine.NewSession(first.Session, b.Name)
+	if first.Node != "" {
+		initial.CurrentNode = first.Node
+	}
+	for name, value := range first.Before {
+		initial.Variables[name] = value
+	}
+	initial.Stack = first.Stack
+	if first.History != nil {
+		initial.History = first.History
+	}
+	initial.Retries = first.Retries
+	if err := store.Save(initial); err != nil {
+		return Result{}, err
+	}
+
+	provider := &replayProvider{}
+	replayed := &collector{}
+	opts = append(append([]engine.Option(nil), opts...), engine.WithStore(store), engine.WithRecorder(replayed))
+	ce := engine.NewConversationEngine(b, provider, opts...)
+	if err := ce.OpenSession(first.Session); err != nil {
+		result.Turns = 1
+		result.Divergence = &Divergence{
+			Turn:        1,
+			Action:      first.Action,
+			Input:       first.Input,
+			Differences: []string{err.Error()},
+		}
+		return result, nil
+	}
+	sessionID := ce.SessionID()
+
+	for i, want := range recorded {
+		provider.load(want.LLM)
+		switch want.Action {
+		case "start":
+			_, _ = ce.Start(ctx, sessionID)
+		case "step":
+			_, _ = ce.Step(ctx, sessionID, want.Input)
+		case "back":
+			_, _ = ce.Back(ctx, sessionID)
+		default:
+			return Result{}, fmt.Errorf("session %s turn %d has unknown action '%s'", want.Session, i+1, want.Action)
+		}
+		result.Turns++
+
+		// Failed turns are recorded too, so errors are compared as behavior
+		got := replayed.turns[len(replayed.turns)-1]
+		differences := append(compare(want, got), provider.mismatches...)
+		differences = append(differences, provider.unused()...)
+		if len(differences) > 0 {
+			result.Divergence = &Divergence{
+				Turn:        i + 1,
+				Action:      want.Action,
+				Input:       want.Input,
+				Differences: differences,
+			}
+			break
+		}
+	}
+	return result, nil
+}
+
+// compare describes how a replayed turn differs from its recording
+func compare(want, got engine.TurnRecord) []string {
+	var differences []string
+	if want.Node != got.Node {
+		differences = append(differences, fmt.Sprintf("started at: recorded %s, replayed %s", want.Node, got.Node))
+	}
+	if describeRoute(want.Route) != describeRoute(got.Route) {
+		differences = append(differences, fmt.Sprintf("route: recorded %s, replayed %s", describeRoute(want.Route), describeRoute(got.Route)))
+	}
+	if describeBranch(want.Branch) != describeBranch(got.Branch) {
+		differences = append(differences, fmt.Sprintf("branch: recorded %s, replayed %s", describeBranch(want.Branch), describeBranch(got.Branch)))
+	}
+	if want.Next != got.Next {
+		differences = append(differences, fmt.Sprintf("node: recorded %s, replayed %s", want.Next, got.Next))
+	}
+	if want.Error != got.Error {
+		differences = append(differences, fmt.Sprintf("error: recorded %q, replayed %q", want.Error, got.Error))
+	}
+	if wantText, gotText := strings.Join(want.Messages, "\n"), strings.Join(got.Messages, "\n"); wantText != gotText {
+		differences = append(differences, "messages differ (- recorded, + replayed):\n"+textdiff.Diff(wantText, gotText))
+	}
+	differences = append(differences, compareVariables(want.After, got.After)...)
+	if want.Terminal != got.Terminal {
+		differences = append(differences, fmt.Sprintf("terminal: recorded %t, replayed %t", want.Terminal, got.Terminal))
+	}
+	return differences
+}
+
+// compareVariables describes the variables whose values differ
+func compareVariables(want, got map[string]string) []string {
+	names := make(map[string]bool)
+	for name := range want {
+		names[name] = true
+	}
+	for name := range got {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var differences []string
+	for _, name := range sorted {
+		wantValue, wantSet := want[name]
+		gotValue, gotSet := got[name]
+		switch {
+		case !gotSet:
+			differences = append(differences, fmt.Sprintf("variable %s: recorded %q, replayed not set", name, wantValue))
+		case !wantSet:
+			differences = append(differences, fmt.Sprintf("variable %s: recorded not set, replayed %q", name, gotValue))
+		case wantValue != gotValue:
+			differences = append(differences, fmt.Sprintf("variable %s: recorded %q, replayed %q", name, wantValue, gotValue))
+		}
+	}
+	return differences
+}
+
+// describeRoute writes a routing decision for comparison and display
+func describeRoute(route *engine.RouteRecord) string {
+	switch {
+	case route == nil:
+		return "no routing"
+	case route.Intent == "":
+		return "no match"
+	case route.Global:
+		return fmt.Sprintf("global intent '%s' (%s router)", route.Intent, route.Router)
+	default:
+		return fmt.Sprintf("intent '%s' (%s router)", route.Intent, route.Router)
+	}
+}
+
+// describeBranch writes a branch decision for comparison and display
+func describeBranch(branch *int) string {
+	switch {
+	case branch == nil:
+		return "none"
+	case *branch < 0:
+		return "next"
+	default:
+		return fmt.Sprintf("branch %d", *branch+1)
+	}
+}
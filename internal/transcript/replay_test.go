@@ -0,0 +1,84 @@
+package transcript
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"chatbot-go/internal/bot"
+	"chatbot-go/internal/engine"
+	"chatbot-go/internal/llm"
+)
+
+// testBot asks for an age, giving up after two rejected answers
+const testBot = `
+bot:
+  name: ages
+flows:
+  start:
+    message: "Age?"
+    input: {type: integer, save_as: age, max_retries: 2, fallback: help}
+    next: done
+  done:
+    message: "You are {{age}}"
+  help:
+    message: "Let's find someone to help"
+`
+
+// loadTestBot loads a bot definition written inline in a test
+func loadTestBot(t *testing.T, definition string) *bot.Bot {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bot.yaml")
+	if err := os.WriteFile(path, []byte(definition), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b, err := bot.LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("failed to load bot: %v", err)
+	}
+	return b
+}
+
+func TestReplayResumesRecordedSessionState(t *testing.T) {
+	b := loadTestBot(t, testBot)
+	recorded := &collector{}
+	ce := engine.NewConversationEngine(b, llm.NewNoopProvider(), engine.WithRecorder(recorded))
+	ctx := context.Background()
+
+	retried, err := ce.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ce.Start(ctx, retried)
+	ce.Step(ctx, retried, "soon")
+	ce.Step(ctx, retried, "later") // second rejection reaches help
+
+	wentBack, err := ce.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ce.Start(ctx, wentBack)
+	ce.Step(ctx, wentBack, "42")
+	ce.Back(ctx, wentBack)
+
+	// Keep only the last turn of each session, as in a transcript of a
+	// resumed session whose earlier turns were not recorded
+	var turns []engine.TurnRecord
+	for _, session := range Sessions(recorded.turns) {
+		turns = append(turns, session[len(session)-1])
+	}
+	if turns[0].Next != "help" || turns[1].Next != "start" {
+		t.Fatalf("unexpected recording: %+v", turns)
+	}
+
+	results, err := Replay(ctx, b, turns)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	for _, result := range results {
+		if result.Divergence != nil {
+			t.Errorf("session %s diverged: %+v", result.Session, result.Divergence)
+		}
+	}
+}
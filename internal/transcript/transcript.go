@@ -0,0 +1,115 @@
+package transcript
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"chatbot-go/internal/engine"
+)
+
+// Writer appends turn records to a transcript, one JSON object per line.
+// It is safe for concurrent use.
+type Writer struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+}
+
+// NewWriter creates a transcript writer on w
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w, enc: json.NewEncoder(w)}
+}
+
+// Create opens a transcript file for appending, creating it if needed
+func Create(path string) (*Writer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcript: %w", err)
+	}
+	return NewWriter(f), nil
+}
+
+// Record writes a turn record (implements engine.Recorder)
+func (w *Writer) Record(turn engine.TurnRecord) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.enc.Encode(turn)
+}
+
+// Close closes the underlying file, if the writer owns one
+func (w *Writer) Close() error {
+	if closer, ok := w.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Read reads every turn record of a transcript
+func Read(r io.Reader) ([]engine.TurnRecord, error) {
+	var turns []engine.TurnRecord
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var turn engine.TurnRecord
+		if err := json.Unmarshal(scanner.Bytes(), &turn); err != nil {
+			return nil, fmt.Errorf("invalid transcript line %d: %w", line, err)
+		}
+		turns = append(turns, turn)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	return turns, nil
+}
+
+// ReadFile reads every turn record of a transcript file
+func ReadFile(path string) ([]engine.TurnRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcript: %w", err)
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Sessions groups turn records by session, in the order each session first
+// appears
+func Sessions(turns []engine.TurnRecord) [][]engine.TurnRecord {
+	var sessions [][]engine.TurnRecord
+	index := make(map[string]int)
+	for _, turn := range turns {
+		i, seen := index[turn.Session]
+		if !seen {
+			i = len(sessions)
+			index[turn.Session] = i
+			sessions = append(sessions, nil)
+		}
+		sessions[i] = append(sessions[i], turn)
+	}
+	return sessions
+}
+
+// Path returns the nodes a session's turns went through, in order
+func Path(turns []engine.TurnRecord) []string {
+	var path []string
+	visit := func(name string) {
+		if len(path) == 0 || path[len(path)-1] != name {
+			path = append(path, name)
+		}
+	}
+	for _, turn := range turns {
+		visit(turn.Node)
+		for _, name := range turn.Visited {
+			visit(name)
+		}
+		visit(turn.Next)
+	}
+	return path
+}
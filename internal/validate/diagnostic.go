@@ -0,0 +1,80 @@
+package validate
+
+import (
+	"chatbot-go/internal/bot"
+	"fmt"
+)
+
+// Severity ranks a diagnostic
+type Severity string
+
+const (
+	// SeverityError marks a problem that stops the bot from running correctly
+	SeverityError Severity = "error"
+
+	// SeverityWarning marks a likely mistake that does not stop the bot
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a single problem found in a bot definition
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Path     string   `json:"path,omitempty"` // YAML key path, e.g. flows.start.next
+	Message  string   `json:"message"`
+}
+
+// String formats the diagnostic as file:line:column: severity: message
+func (d Diagnostic) String() string {
+	location := d.File
+	if d.Line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+	}
+	if location == "" {
+		return fmt.Sprintf("%s: %s", d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, d.Severity, d.Message)
+}
+
+// HasErrors reports whether any diagnostic is an error
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// report collects diagnostics, locating each in the bot's file
+type report struct {
+	bot         *bot.Bot
+	diagnostics []Diagnostic
+}
+
+// errorf records an error at a key path
+func (r *report) errorf(path, format string, args ...any) {
+	r.add(SeverityError, path, fmt.Sprintf(format, args...))
+}
+
+// warnf records a warning at a key path
+func (r *report) warnf(path, format string, args ...any) {
+	r.add(SeverityWarning, path, fmt.Sprintf(format, args...))
+}
+
+// add records a diagnostic
+func (r *report) add(severity Severity, path, message string) {
+	d := Diagnostic{
+		Severity: severity,
+		File:     r.bot.File(),
+		Path:     path,
+		Message:  message,
+	}
+	if pos, ok := r.bot.Position(path); ok {
+		d.Line = pos.Line
+		d.Column = pos.Column
+	}
+	r.diagnostics = append(r.diagnostics, d)
+}
@@ -2,34 +2,436 @@ package validate
 
 import (
 	"chatbot-go/internal/bot"
+	"chatbot-go/internal/tmpl"
+	"errors"
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 )
 
-// ValidateFlow performs comprehensive flow validation
+// ValidateFlow performs comprehensive flow validation and returns the first
+// error found; use Check to get every problem
 func ValidateFlow(b *bot.Bot) error {
+	for _, d := range Check(b) {
+		if d.Severity == SeverityError {
+			return errors.New(d.Message)
+		}
+	}
+	return nil
+}
+
+// Check runs every validation and returns all errors and warnings, ordered
+// by their position in the bot file
+func Check(b *bot.Bot) []Diagnostic {
+	r := &report{bot: b}
+
 	// Basic validation
-	if err := b.ValidateBasic(); err != nil {
-		return err
+	if b.Name == "" {
+		r.errorf("bot.name", "bot name is required")
+	}
+	if len(b.Flows) == 0 {
+		r.errorf("flows", "at least one flow node is required")
+	} else if _, exists := b.Flows["start"]; !exists {
+		r.errorf("flows", "flow must contain a 'start' node")
 	}
 
-	// Check all referenced nodes exist
-	for nodeName, node := range b.Flows {
+	// Check each node's references and settings
+	for _, nodeName := range nodeNames(b) {
+		node := b.Flows[nodeName]
+		path := "flows." + nodeName
+		if node == nil {
+			r.errorf(path, "node '%s' is empty", nodeName)
+			continue
+		}
+
 		// Check next node
 		if node.Next != "" {
 			if _, exists := b.Flows[node.Next]; !exists {
-				return fmt.Errorf("node '%s' references non-existent next node '%s'", nodeName, node.Next)
+				r.errorf(path+".next", "node '%s' references non-existent next node '%s'", nodeName, node.Next)
 			}
 		}
 
 		// Check intent next nodes
-		for _, intent := range node.Intents {
+		for i, intent := range node.Intents {
 			if intent.Next != "" {
 				if _, exists := b.Flows[intent.Next]; !exists {
-					return fmt.Errorf("node '%s' intent '%s' references non-existent next node '%s'", nodeName, intent.Name, intent.Next)
+					r.errorf(fmt.Sprintf("%s.intents.%d.next", path, i), "node '%s' intent '%s' references non-existent next node '%s'", nodeName, intent.Name, intent.Next)
 				}
 			}
 		}
+
+		// Check fields that conflict or are incomplete
+		checkNodeShape(r, nodeName, node)
+
+		// Check actions
+		checkActions(r, nodeName, node)
+
+		// Check message templates
+		checkTemplates(r, nodeName, node)
+
+		// Check input capture
+		checkInput(r, b, nodeName, node)
+
+		// Check conditional branches
+		checkBranches(r, b, nodeName, node)
+
+		// Check sub-flow calls
+		checkCall(r, b, nodeName, node)
+
+		// Check unmatched-intent fallback
+		checkNodeFallback(r, b, nodeName, node)
 	}
 
-	return nil
+	if b.Fallback != nil {
+		checkFallback(r, b, "fallback", "bot", b.Fallback)
+	}
+
+	checkGlobalIntents(r, b)
+	checkSubflows(r, b)
+	checkHelperPlaceholders(r, b)
+
+	// Graph analysis needs every reference to resolve
+	if _, exists := b.Flows["start"]; exists && !HasErrors(r.diagnostics) {
+		checkReachability(r, b)
+		checkIntentOverlaps(r, b)
+		checkVariables(r, b)
+	}
+
+	sort.SliceStable(r.diagnostics, func(i, j int) bool {
+		return r.diagnostics[i].Line < r.diagnostics[j].Line
+	})
+	return r.diagnostics
+}
+
+// nodeNames returns the bot's node names in a stable order
+func nodeNames(b *bot.Bot) []string {
+	names := make([]string, 0, len(b.Flows))
+	for name := range b.Flows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkTemplates checks a node's message and generate prompt compile
+func checkTemplates(r *report, nodeName string, node *bot.Node) {
+	if _, err := tmpl.Parse(nodeName, node.Message); err != nil {
+		r.errorf("flows."+nodeName+".message", "node '%s' message: %v", nodeName, err)
+	}
+	if _, err := tmpl.Parse(nodeName, node.Generate); err != nil {
+		r.errorf("flows."+nodeName+".generate", "node '%s' generate prompt: %v", nodeName, err)
+	}
+}
+
+// checkGlobalIntents checks global intent priority and targets
+func checkGlobalIntents(r *report, b *bot.Bot) {
+	if b.GlobalIntents == nil {
+		return
+	}
+
+	switch b.GlobalIntents.Priority {
+	case "", "before", "after":
+	default:
+		r.errorf("global_intents.priority", "global intents priority must be 'before' or 'after', got '%s'", b.GlobalIntents.Priority)
+	}
+
+	seen := make(map[string]bool)
+	for i, intent := range b.GlobalIntents.Intents {
+		path := fmt.Sprintf("global_intents.intents.%d", i)
+		if intent.Name == "" {
+			r.errorf(path, "global intent requires a name")
+		} else if seen[intent.Name] {
+			r.errorf(path+".name", "duplicate global intent '%s'", intent.Name)
+		}
+		seen[intent.Name] = true
+
+		switch {
+		case intent.Next != "" && intent.Builtin != "":
+			r.errorf(path, "global intent '%s' cannot declare both next and builtin", intent.Name)
+		case intent.Builtin != "":
+			if intent.Builtin != "restart" && intent.Builtin != "back" {
+				r.errorf(path+".builtin", "global intent '%s' has unknown builtin '%s'", intent.Name, intent.Builtin)
+			}
+		case intent.Next != "":
+			if _, exists := b.Flows[intent.Next]; !exists {
+				r.errorf(path+".next", "global intent '%s' references non-existent next node '%s'", intent.Name, intent.Next)
+			}
+		default:
+			r.errorf(path, "global intent '%s' requires a next node or a builtin", intent.Name)
+		}
+	}
+}
+
+// checkCall checks a call node references a declared sub-flow and a return node
+func checkCall(r *report, b *bot.Bot, nodeName string, node *bot.Node) {
+	if node.Call == nil {
+		return
+	}
+	path := "flows." + nodeName + ".call"
+	if node.Input != nil || len(node.Intents) > 0 || len(node.Branches) > 0 || node.Next != "" {
+		r.errorf(path, "node '%s' calls a sub-flow and cannot also declare input, intents, branches or next", nodeName)
+	}
+	if _, exists := b.Subflows[node.Call.Flow]; !exists {
+		r.errorf(path+".flow", "node '%s' calls non-existent sub-flow '%s'", nodeName, node.Call.Flow)
+	}
+	if node.Call.Return == "" {
+		r.errorf(path, "node '%s' call to sub-flow '%s' has no return node", nodeName, node.Call.Flow)
+	} else if _, exists := b.Flows[node.Call.Return]; !exists {
+		r.errorf(path+".return", "node '%s' call references non-existent return node '%s'", nodeName, node.Call.Return)
+	}
+}
+
+// checkSubflows checks every sub-flow has a start node, can reach an end
+// to return from, and does not call itself directly or indirectly
+func checkSubflows(r *report, b *bot.Bot) {
+	names := make([]string, 0, len(b.Subflows))
+	for name := range b.Subflows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	calls := make(map[string][]string)
+	for _, name := range names {
+		subflow := b.Subflows[name]
+		path := "subflows." + name
+		if subflow == nil {
+			r.errorf(path, "sub-flow '%s' is empty", name)
+			continue
+		}
+		if _, exists := b.Flows[subflow.Start]; !exists {
+			r.errorf(path+".start", "sub-flow '%s' references non-existent start node '%s'", name, subflow.Start)
+			continue
+		}
+
+		reachesEnd := false
+		for _, nodeName := range walkFlow(b, subflow.Start) {
+			node := b.Flows[nodeName]
+			if node == nil {
+				continue
+			}
+			if node.Call != nil {
+				calls[name] = append(calls[name], node.Call.Flow)
+			}
+			if node.EndsFlow() {
+				reachesEnd = true
+			}
+		}
+		if !reachesEnd {
+			r.errorf(path, "sub-flow '%s' never reaches an end node to return from", name)
+		}
+	}
+
+	// Detect recursion in the sub-flow call graph
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	var visit func(name string, path []string)
+	visit = func(name string, path []string) {
+		switch state[name] {
+		case visiting:
+			r.errorf("subflows."+path[0], "sub-flow recursion: %s", strings.Join(append(path, name), " -> "))
+			return
+		case done:
+			return
+		}
+		state[name] = visiting
+		for _, callee := range calls[name] {
+			visit(callee, append(path, name))
+		}
+		state[name] = done
+	}
+
+	for _, name := range names {
+		visit(name, nil)
+	}
+}
+
+// walkFlow returns the nodes reachable from start without entering called
+// sub-flows; call nodes continue at their return node
+func walkFlow(b *bot.Bot, start string) []string {
+	var order []string
+	seen := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		order = append(order, name)
+
+		node, exists := b.Flows[name]
+		if !exists || node == nil {
+			continue
+		}
+		for _, next := range successors(b, node) {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return order
+}
+
+// successors returns the nodes a node can transition to within its own flow
+func successors(b *bot.Bot, node *bot.Node) []string {
+	var next []string
+	if node.Next != "" {
+		next = append(next, node.Next)
+	}
+	for _, intent := range node.Intents {
+		if intent.Next != "" {
+			next = append(next, intent.Next)
+		}
+	}
+	for _, branch := range node.Branches {
+		next = append(next, branch.Next)
+	}
+	if node.Call != nil && node.Call.Return != "" {
+		next = append(next, node.Call.Return)
+	}
+	if node.Input != nil && node.Input.Fallback != "" {
+		next = append(next, node.Input.Fallback)
+	}
+	if len(node.Intents) > 0 {
+		if fallback := b.FallbackFor(node); fallback.Next != "" {
+			next = append(next, fallback.Next)
+		}
+	}
+	return next
+}
+
+// checkInput checks an input's type options and retry fallback
+func checkInput(r *report, b *bot.Bot, nodeName string, node *bot.Node) {
+	in := node.Input
+	if in == nil {
+		return
+	}
+	path := "flows." + nodeName + ".input"
+
+	switch in.Type {
+	case "text", "", "number", "integer", "email", "phone", "date", "time", "yes_no":
+	case "choice":
+		if len(in.Options) == 0 {
+			r.errorf(path, "node '%s' choice input requires options", nodeName)
+		}
+	case "regex":
+		if in.Pattern == "" {
+			r.errorf(path, "node '%s' regex input requires a pattern", nodeName)
+		} else if _, err := regexp.Compile(in.Pattern); err != nil {
+			r.errorf(path+".pattern", "node '%s' input has invalid pattern '%s': %v", nodeName, in.Pattern, err)
+		}
+	default:
+		r.errorf(path+".type", "node '%s' has unknown input type '%s'", nodeName, in.Type)
+	}
+
+	if node.Next == "" && len(node.Branches) == 0 {
+		r.errorf(path, "node '%s' takes input but has no next node to continue to once it is captured", nodeName)
+	}
+
+	if in.MaxRetries < 0 {
+		r.errorf(path+".max_retries", "node '%s' input max_retries cannot be negative", nodeName)
+	}
+	if (in.MaxRetries > 0) != (in.Fallback != "") {
+		r.errorf(path, "node '%s' input max_retries and fallback must be set together", nodeName)
+	}
+	if in.Fallback != "" {
+		if _, exists := b.Flows[in.Fallback]; !exists {
+			r.errorf(path+".fallback", "node '%s' input references non-existent fallback node '%s'", nodeName, in.Fallback)
+		}
+	}
+}
+
+// checkNodeFallback checks the fallback in effect at an intent node
+func checkNodeFallback(r *report, b *bot.Bot, nodeName string, node *bot.Node) {
+	path := "flows." + nodeName + ".fallback"
+	if node.Fallback != nil {
+		if len(node.Intents) == 0 {
+			r.errorf(path, "node '%s' declares a fallback but has no intents", nodeName)
+		}
+		checkFallback(r, b, path, fmt.Sprintf("node '%s'", nodeName), node.Fallback)
+	}
+	if len(node.Intents) == 0 {
+		return
+	}
+
+	fallback := b.FallbackFor(node)
+	if (fallback.MaxMisses > 0) != (fallback.Next != "") {
+		r.errorf(path, "node '%s' fallback max_misses and next must be set together", nodeName)
+	}
+}
+
+// checkFallback checks a fallback's escalation settings
+func checkFallback(r *report, b *bot.Bot, path, owner string, fallback *bot.Fallback) {
+	if fallback.MaxMisses < 0 {
+		r.errorf(path+".max_misses", "%s fallback max_misses cannot be negative", owner)
+	}
+	if fallback.Next != "" {
+		if _, exists := b.Flows[fallback.Next]; !exists {
+			r.errorf(path+".next", "%s fallback references non-existent next node '%s'", owner, fallback.Next)
+		}
+	}
+}
+
+// checkBranches checks a node's branch targets and conditions
+func checkBranches(r *report, b *bot.Bot, nodeName string, node *bot.Node) {
+	if len(node.Branches) == 0 {
+		return
+	}
+	path := "flows." + nodeName + ".branches"
+	if len(node.Intents) > 0 {
+		r.errorf(path, "node '%s' cannot declare both intents and branches", nodeName)
+	}
+
+	hasDefault := false
+	for i, branch := range node.Branches {
+		branchPath := fmt.Sprintf("%s.%d", path, i)
+		if branch.Next == "" {
+			r.errorf(branchPath, "node '%s' branch %d has no next node", nodeName, i+1)
+		} else if _, exists := b.Flows[branch.Next]; !exists {
+			r.errorf(branchPath+".next", "node '%s' branch %d references non-existent next node '%s'", nodeName, i+1, branch.Next)
+		}
+
+		if branch.When == nil {
+			if i != len(node.Branches)-1 {
+				r.errorf(branchPath, "node '%s' default branch must be the last branch", nodeName)
+			}
+			hasDefault = true
+			continue
+		}
+		if err := validateCondition(branch.When); err != nil {
+			r.errorf(branchPath+".when", "node '%s' branch %d: %v", nodeName, i+1, err)
+		}
+	}
+
+	if !hasDefault && node.Next == "" {
+		r.errorf(path, "node '%s' branches need a default branch or a next node", nodeName)
+	}
+}
+
+// validateCondition checks a branch condition is well-formed
+func validateCondition(cond *bot.Condition) error {
+	if cond.Var == "" {
+		return fmt.Errorf("condition requires 'var'")
+	}
+
+	switch cond.Op {
+	case "eq", "", "ne", "exists", "missing":
+		return nil
+	case "matches":
+		if _, err := regexp.Compile(cond.Value); err != nil {
+			return fmt.Errorf("invalid pattern '%s': %w", cond.Value, err)
+		}
+		return nil
+	case "gt", "gte", "lt", "lte":
+		if _, err := strconv.ParseFloat(cond.Value, 64); err != nil {
+			return fmt.Errorf("operator '%s' requires a numeric value, got '%s'", cond.Op, cond.Value)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown condition operator '%s'", cond.Op)
+	}
 }
@@ -0,0 +1,62 @@
+package validate
+
+import (
+	"chatbot-go/internal/bot"
+	"fmt"
+)
+
+// checkNodeShape checks a node does not combine fields the engine cannot
+// honour together, and that its input and intents are complete
+func checkNodeShape(r *report, nodeName string, node *bot.Node) {
+	path := "flows." + nodeName
+
+	// Input nodes capture any reply, so their intents would never be routed
+	if node.Input != nil && len(node.Intents) > 0 {
+		r.errorf(path, "node '%s' cannot declare both input and intents", nodeName)
+	}
+
+	if node.Input != nil && node.Input.SaveAs == "" {
+		r.errorf(path+".input", "node '%s' input requires save_as", nodeName)
+	}
+
+	seen := make(map[string]bool)
+	for i, intent := range node.Intents {
+		intentPath := fmt.Sprintf("%s.intents.%d", path, i)
+		if intent.Name == "" {
+			r.errorf(intentPath, "node '%s' intent %d requires a name", nodeName, i+1)
+			continue
+		}
+		if seen[intent.Name] {
+			r.errorf(intentPath+".name", "node '%s' has duplicate intent '%s'", nodeName, intent.Name)
+		}
+		seen[intent.Name] = true
+
+		if intent.Next == "" {
+			r.warnf(intentPath, "node '%s' intent '%s' has no next node, so matching it stays on the node", nodeName, intent.Name)
+		}
+	}
+}
+
+// checkActions checks a node's actions have a known type and the arguments
+// that type needs
+func checkActions(r *report, nodeName string, node *bot.Node) {
+	for i, action := range node.Actions {
+		path := fmt.Sprintf("flows.%s.actions.%d", nodeName, i)
+
+		switch action.Type {
+		case "set_var":
+			if name, ok := action.Args["name"].(string); !ok || name == "" {
+				r.errorf(path+".args", "node '%s' set_var action requires a 'name' argument", nodeName)
+			}
+			if value, ok := action.Args["value"]; ok {
+				if _, isString := value.(string); !isString {
+					r.errorf(path+".args.value", "node '%s' set_var value must be a string", nodeName)
+				}
+			}
+		case "":
+			r.errorf(path, "node '%s' action %d requires a type", nodeName, i+1)
+		default:
+			r.errorf(path+".type", "node '%s' has unknown action type '%s'", nodeName, action.Type)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package validate
+
+import (
+	"chatbot-go/internal/bot"
+	"chatbot-go/internal/router"
+	"fmt"
+)
+
+// NodeOverlap is an intent example in a node that routes to another intent
+type NodeOverlap struct {
+	Node string `json:"node,omitempty"` // empty for global intents
+	router.Overlap
+}
+
+// FindOverlaps checks the intent examples of every node, and of the global
+// intents, with the rule router. Examples that match their own intent exactly
+// are left out: the router always sends them to that intent.
+func FindOverlaps(b *bot.Bot) []NodeOverlap {
+	rules := router.NewRuleRouter()
+
+	var overlaps []NodeOverlap
+	for _, nodeName := range nodeNames(b) {
+		node := b.Flows[nodeName]
+		if node == nil {
+			continue
+		}
+		for _, overlap := range shadowed(rules, node.Intents) {
+			overlaps = append(overlaps, NodeOverlap{Node: nodeName, Overlap: overlap})
+		}
+	}
+
+	if b.GlobalIntents != nil {
+		intents := make([]bot.Intent, len(b.GlobalIntents.Intents))
+		for i, global := range b.GlobalIntents.Intents {
+			intents[i] = global.Intent
+		}
+		for _, overlap := range shadowed(rules, intents) {
+			overlaps = append(overlaps, NodeOverlap{Overlap: overlap})
+		}
+	}
+	return overlaps
+}
+
+// shadowed returns the overlaps among intents whose example does not match
+// its own intent exactly
+func shadowed(rules router.Router, intents []bot.Intent) []router.Overlap {
+	var overlaps []router.Overlap
+	for _, overlap := range router.FindOverlaps(rules, intents) {
+		if matched, ok := router.MatchExact(overlap.Example, intents); ok && matched == overlap.Intent {
+			continue
+		}
+		overlaps = append(overlaps, overlap)
+	}
+	return overlaps
+}
+
+// Describe explains the overlap in one line
+func (o NodeOverlap) Describe() string {
+	owner := "global intent"
+	if o.Node != "" {
+		owner = fmt.Sprintf("node '%s' intent", o.Node)
+	}
+	if o.Duplicate {
+		return fmt.Sprintf("%s '%s' example '%s' is also an example of '%s'", owner, o.Intent, o.Example, o.RoutedTo)
+	}
+	return fmt.Sprintf("%s '%s' example '%s' routes to '%s' when not matched exactly", owner, o.Intent, o.Example, o.RoutedTo)
+}
+
+// checkIntentOverlaps warns about intent examples that route to another intent
+func checkIntentOverlaps(r *report, b *bot.Bot) {
+	for _, overlap := range FindOverlaps(b) {
+		path := "global_intents"
+		if overlap.Node != "" {
+			path = "flows." + overlap.Node
+		}
+		path = fmt.Sprintf("%s.intents.%d.examples.%d", path, overlap.IntentIndex, overlap.ExampleIndex)
+		r.warnf(path, "%s", overlap.Describe())
+	}
+}
@@ -0,0 +1,289 @@
+package validate
+
+import (
+	"chatbot-go/internal/bot"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// checkReachability reports nodes nothing points to, nodes unreachable from
+// start, cycles the user cannot type their way out of, and nodes from which
+// the conversation can never end. It assumes the flow's references are valid.
+func checkReachability(r *report, b *bot.Bot) {
+	graph := transitions(b)
+	referenced := referencedNodes(b)
+
+	reachable := reach(graph, []string{"start"})
+	for _, name := range nodeNames(b) {
+		switch {
+		case name == "start" || reachable[name]:
+		case !referenced[name]:
+			r.warnf("flows."+name, "node '%s' is never referenced", name)
+		default:
+			r.warnf("flows."+name, "node '%s' is unreachable from start", name)
+		}
+	}
+
+	trapped := checkAutoCycles(r, b, reachable)
+
+	// Nodes that cannot reach a terminal node keep the conversation going forever
+	var terminals []string
+	for _, name := range walkFlow(b, "start") {
+		if b.Flows[name].EndsFlow() {
+			terminals = append(terminals, name)
+		}
+	}
+	if len(terminals) == 0 {
+		r.warnf("flows.start", "no terminal node is reachable from start, so conversations never end")
+		return
+	}
+
+	reverse := make(map[string][]string)
+	for from, targets := range graph {
+		for _, to := range targets {
+			reverse[to] = append(reverse[to], from)
+		}
+	}
+	ending := reach(reverse, terminals)
+	for _, name := range nodeNames(b) {
+		if reachable[name] && !ending[name] && !trapped[name] {
+			r.warnf("flows."+name, "node '%s' can never reach a terminal node", name)
+		}
+	}
+}
+
+// checkAutoCycles reports reachable cycles of nodes without input or intents.
+// Such nodes accept any reply and move on, so the conversation goes round the
+// cycle until a branch leaves it, or forever if none can; the nodes of those
+// inescapable cycles are returned.
+func checkAutoCycles(r *report, b *bot.Bot, reachable map[string]bool) map[string]bool {
+	auto := make(map[string][]string)
+	for name, node := range b.Flows {
+		if reachable[name] && node.Input == nil && len(node.Intents) == 0 {
+			auto[name] = ownTransitions(b, name, node)
+		}
+	}
+
+	trapped := make(map[string]bool)
+	for _, component := range stronglyConnected(auto) {
+		first := component[0]
+		if len(component) == 1 && !contains(auto[first], first) {
+			continue
+		}
+
+		members := make(map[string]bool, len(component))
+		for _, name := range component {
+			members[name] = true
+		}
+		exits := false
+		for _, name := range component {
+			for _, next := range auto[name] {
+				if !members[next] {
+					exits = true
+				}
+			}
+		}
+
+		cycle := fmt.Sprintf("nodes %s form a cycle", strings.Join(component, ", "))
+		if len(component) == 1 {
+			cycle = fmt.Sprintf("node '%s' loops to itself", first)
+		}
+		switch {
+		case exits:
+			r.warnf("flows."+first, "%s with no input or intents that only ends when a branch leaves it", cycle)
+		case hasGlobalJump(b):
+			r.warnf("flows."+first, "%s with no input or intents that can only be left through a global intent", cycle)
+		default:
+			r.errorf("flows."+first, "%s with no input or intents and can never be left", cycle)
+			for _, name := range component {
+				trapped[name] = true
+			}
+		}
+	}
+	return trapped
+}
+
+// hasGlobalJump reports whether a global intent can move the conversation
+// to another node
+func hasGlobalJump(b *bot.Bot) bool {
+	if b.GlobalIntents == nil {
+		return false
+	}
+	for _, global := range b.GlobalIntents.Intents {
+		if global.Builtin == "restart" || global.Next != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// transitions returns every node each node can move to: its own transitions,
+// its fallbacks, and the global intents that can be triggered from it.
+// Terminal nodes accept no input, so they have no transitions.
+func transitions(b *bot.Bot) map[string][]string {
+	terminal := make(map[string]bool)
+	for _, name := range walkFlow(b, "start") {
+		if b.Flows[name].EndsFlow() {
+			terminal[name] = true
+		}
+	}
+
+	var globals []string
+	if b.GlobalIntents != nil {
+		for _, global := range b.GlobalIntents.Intents {
+			switch {
+			case global.Builtin == "restart":
+				globals = append(globals, "start")
+			case global.Next != "":
+				globals = append(globals, global.Next)
+			}
+		}
+	}
+
+	graph := make(map[string][]string, len(b.Flows))
+	for name, node := range b.Flows {
+		if terminal[name] {
+			continue
+		}
+		next := ownTransitions(b, name, node)
+		if node.Input != nil && node.Input.Fallback != "" {
+			next = append(next, node.Input.Fallback)
+		}
+		if len(node.Intents) > 0 {
+			if fallback := b.FallbackFor(node); fallback.Next != "" {
+				next = append(next, fallback.Next)
+			}
+		}
+		graph[name] = append(next, globals...)
+	}
+	return graph
+}
+
+// ownTransitions returns the nodes a node moves to when it handles input:
+// its next node, intents and branches, the sub-flow it calls, and, at the
+// end of a sub-flow, the nodes its callers return to
+func ownTransitions(b *bot.Bot, name string, node *bot.Node) []string {
+	next := completedSuccessors(b, name, node)
+	if node.Call != nil && node.Call.Return != "" {
+		next = append(next, node.Call.Return)
+	}
+	return next
+}
+
+// referencedNodes returns every node named as a target anywhere in the bot
+func referencedNodes(b *bot.Bot) map[string]bool {
+	referenced := make(map[string]bool)
+	for _, node := range b.Flows {
+		for _, next := range successors(b, node) {
+			referenced[next] = true
+		}
+	}
+	for _, subflow := range b.Subflows {
+		referenced[subflow.Start] = true
+	}
+	if b.Fallback != nil && b.Fallback.Next != "" {
+		referenced[b.Fallback.Next] = true
+	}
+	if b.GlobalIntents != nil {
+		for _, global := range b.GlobalIntents.Intents {
+			if global.Next != "" {
+				referenced[global.Next] = true
+			}
+		}
+	}
+	return referenced
+}
+
+// reach returns the nodes reachable from the given nodes
+func reach(graph map[string][]string, from []string) map[string]bool {
+	seen := make(map[string]bool)
+	queue := append([]string(nil), from...)
+	for _, name := range from {
+		seen[name] = true
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, next := range graph[name] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return seen
+}
+
+// stronglyConnected returns the strongly connected components of a graph
+// restricted to its own nodes, each sorted and in a stable order
+func stronglyConnected(graph map[string][]string) [][]string {
+	names := make([]string, 0, len(graph))
+	for name := range graph {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var (
+		index      = make(map[string]int)
+		lowlink    = make(map[string]int)
+		onStack    = make(map[string]bool)
+		stack      []string
+		components [][]string
+		visit      func(name string)
+	)
+	visit = func(name string) {
+		index[name] = len(index)
+		lowlink[name] = index[name]
+		stack = append(stack, name)
+		onStack[name] = true
+
+		for _, next := range graph[name] {
+			if _, inGraph := graph[next]; !inGraph {
+				continue
+			}
+			if _, visited := index[next]; !visited {
+				visit(next)
+				lowlink[name] = min(lowlink[name], lowlink[next])
+			} else if onStack[next] {
+				lowlink[name] = min(lowlink[name], index[next])
+			}
+		}
+
+		if lowlink[name] == index[name] {
+			var component []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == name {
+					break
+				}
+			}
+			sort.Strings(component)
+			components = append(components, component)
+		}
+	}
+
+	for _, name := range names {
+		if _, visited := index[name]; !visited {
+			visit(name)
+		}
+	}
+
+	sort.Slice(components, func(i, j int) bool {
+		return components[i][0] < components[j][0]
+	})
+	return components
+}
+
+// contains reports whether names includes name
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
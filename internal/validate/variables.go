@@ -0,0 +1,218 @@
+package validate
+
+import (
+	"chatbot-go/internal/bot"
+	"chatbot-go/internal/tmpl"
+	"sort"
+)
+
+// checkVariables walks every path from start and warns when a node's message
+// or generate prompt prints a variable that is not set on some path to it.
+// It assumes the flow's references are valid.
+func checkVariables(r *report, b *bot.Bot) {
+	set := definitelySet(b)
+
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		node := b.Flows[name]
+		for _, field := range []struct{ key, label, text string }{
+			{"message", "message", node.Message},
+			{"generate", "generate prompt", node.Generate},
+		} {
+			for _, variable := range templateVariables(name, field.text) {
+				if !set[name][variable] {
+					r.warnf("flows."+name+"."+field.key, "node '%s' %s uses variable '%s' which may not be set on every path from start", name, field.label, variable)
+				}
+			}
+		}
+	}
+}
+
+// checkHelperPlaceholders reports messages and generate prompts that print a
+// variable with the short {{name}} form when name is also a template helper,
+// since the helper is called instead of the variable being printed
+func checkHelperPlaceholders(r *report, b *bot.Bot) {
+	saved := make(map[string]bool)
+	for _, node := range b.Flows {
+		if node == nil {
+			continue
+		}
+		if node.Input != nil && node.Input.SaveAs != "" {
+			saved[node.Input.SaveAs] = true
+		}
+		for _, action := range node.Actions {
+			if action.Type == "set_var" {
+				if varName, ok := action.Args["name"].(string); ok {
+					saved[varName] = true
+				}
+			}
+		}
+	}
+
+	for _, name := range nodeNames(b) {
+		node := b.Flows[name]
+		if node == nil {
+			continue
+		}
+		for _, field := range []struct{ key, label, text string }{
+			{"message", "message", node.Message},
+			{"generate", "generate prompt", node.Generate},
+		} {
+			for _, helper := range tmpl.HelperPlaceholders(field.text) {
+				if saved[helper] {
+					r.errorf("flows."+name+"."+field.key, "node '%s' %s uses {{%s}}, which calls the %s helper instead of printing variable '%s'; write {{.%s}}", name, field.label, helper, helper, helper, helper)
+				}
+			}
+		}
+	}
+}
+
+// definitelySet computes, for every node reachable from start, the variables
+// set on all paths that reach it. Nodes missing from the result are unreachable.
+func definitelySet(b *bot.Bot) map[string]map[string]bool {
+	in := map[string]map[string]bool{"start": {}}
+	queue := []string{"start"}
+	queued := map[string]bool{"start": true}
+
+	// flow merges a predecessor's variables into a node, keeping only those
+	// set on every path, and queues the node again if that changed anything
+	flow := func(target string, vars map[string]bool) {
+		if _, exists := b.Flows[target]; !exists {
+			return
+		}
+		current, seen := in[target]
+		if !seen {
+			in[target] = copySet(vars)
+		} else {
+			changed := false
+			for name := range current {
+				if !vars[name] {
+					delete(current, name)
+					changed = true
+				}
+			}
+			if !changed {
+				return
+			}
+		}
+		if !queued[target] {
+			queued[target] = true
+			queue = append(queue, target)
+		}
+	}
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		queued[name] = false
+
+		node := b.Flows[name]
+		before := in[name]
+		after := copySet(before)
+		if node.Input != nil && node.Input.SaveAs != "" {
+			after[node.Input.SaveAs] = true
+		}
+		for _, action := range node.Actions {
+			if action.Type == "set_var" {
+				if varName, ok := action.Args["name"].(string); ok {
+					after[varName] = true
+				}
+			}
+		}
+
+		// Transitions taken after the node handled its input
+		for _, next := range completedSuccessors(b, name, node) {
+			flow(next, after)
+		}
+
+		// Transitions taken instead of handling the input
+		if node.Input != nil && node.Input.Fallback != "" {
+			flow(node.Input.Fallback, before)
+		}
+		if len(node.Intents) > 0 {
+			if fallback := b.FallbackFor(node); fallback.Next != "" {
+				flow(fallback.Next, before)
+			}
+		}
+		if b.GlobalIntents != nil {
+			for _, global := range b.GlobalIntents.Intents {
+				if global.Builtin == "" && global.Next != "" {
+					flow(global.Next, before)
+				}
+			}
+		}
+	}
+
+	return in
+}
+
+// completedSuccessors returns the nodes reached once a node has handled its
+// input; a call node enters its sub-flow, and the ends of a sub-flow return
+// to every node that calls it
+func completedSuccessors(b *bot.Bot, name string, node *bot.Node) []string {
+	var next []string
+	if node.Next != "" {
+		next = append(next, node.Next)
+	}
+	for _, intent := range node.Intents {
+		if intent.Next != "" {
+			next = append(next, intent.Next)
+		}
+	}
+	for _, branch := range node.Branches {
+		next = append(next, branch.Next)
+	}
+	if node.Call != nil {
+		if subflow, exists := b.Subflows[node.Call.Flow]; exists {
+			next = append(next, subflow.Start)
+		}
+	}
+
+	if node.EndsFlow() {
+		for subflowName, subflow := range b.Subflows {
+			if !inFlow(b, subflow.Start, name) {
+				continue
+			}
+			for _, caller := range b.Flows {
+				if caller.Call != nil && caller.Call.Flow == subflowName && caller.Call.Return != "" {
+					next = append(next, caller.Call.Return)
+				}
+			}
+		}
+	}
+	return next
+}
+
+// inFlow reports whether a node belongs to the flow entered at start
+func inFlow(b *bot.Bot, start, name string) bool {
+	for _, nodeName := range walkFlow(b, start) {
+		if nodeName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// templateVariables returns the variables a message prints without a guard;
+// templates that do not compile are reported by checkTemplates
+func templateVariables(name, text string) []string {
+	t, err := tmpl.Parse(name, text)
+	if err != nil {
+		return nil
+	}
+	return t.Variables()
+}
+
+// copySet returns a copy of a set of names
+func copySet(set map[string]bool) map[string]bool {
+	out := make(map[string]bool, len(set))
+	for name := range set {
+		out[name] = true
+	}
+	return out
+}